@@ -1,16 +1,21 @@
 package buildsqlx
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"math"
 	"reflect"
-	"strconv"
 )
 
 // First getting the 1st row of query
 func (r *DB) First(src any) error {
-	err := r.ScanStruct(src)
+	return r.FirstCtx(context.Background(), src)
+}
+
+// FirstCtx getting the 1st row of query with the given context
+func (r *DB) FirstCtx(ctx context.Context, src any) error {
+	err := r.ScanStructCtx(ctx, src)
 	if err != nil {
 		return err
 	}
@@ -20,7 +25,13 @@ func (r *DB) First(src any) error {
 
 // Value gets the value of column in first query resulting row
 func (r *DB) Value(src any, column string) error {
-	err := r.Select(column).ScanStruct(src)
+	return r.ValueCtx(context.Background(), src, column)
+}
+
+// ValueCtx gets the value of column in first query resulting row with the given context,
+// the column is selected by the copy, so the query can be reused
+func (r *DB) ValueCtx(ctx context.Context, src any, column string) error {
+	err := r.Clone().Select(column).ScanStructCtx(ctx, src)
 	if err != nil {
 		return err
 	}
@@ -30,12 +41,23 @@ func (r *DB) Value(src any, column string) error {
 
 // Find retrieves a single row by it's id column value
 func (r *DB) Find(src any, id uint64) error {
-	return r.Where("id", "=", id).First(src)
+	return r.FindCtx(context.Background(), src, id)
+}
+
+// FindCtx retrieves a single row by it's id column value with the given context,
+// the id condition is added to the copy, so the query can be reused
+func (r *DB) FindCtx(ctx context.Context, src any, id uint64) error {
+	return r.Clone().Where("id", "=", id).FirstCtx(ctx, src)
 }
 
 // Pluck getting values of a particular column(s) of a struct and place them into slice
 func (r *DB) Pluck(src any) ([]any, error) {
-	res, err := r.eachToStructRows(src, r.Builder.offset, r.Builder.limit)
+	return r.PluckCtx(context.Background(), src)
+}
+
+// PluckCtx getting values of a particular column(s) of a struct and place them into slice with the given context
+func (r *DB) PluckCtx(ctx context.Context, src any) ([]any, error) {
+	res, err := r.eachToStructRows(ctx, src, r.Builder.offset, r.Builder.limit)
 	if err != nil {
 		return nil, err
 	}
@@ -46,12 +68,17 @@ func (r *DB) Pluck(src any) ([]any, error) {
 // PluckMap getting values of a particular key/value columns and place them into map
 // values of the returning map is a structure passed as src and filled with data from DB
 func (r *DB) PluckMap(src any, colKey, colValue string) (val []map[any]any, err error) {
+	return r.PluckMapCtx(context.Background(), src, colKey, colValue)
+}
+
+// PluckMapCtx getting values of a particular key/value columns and place them into map with the given context
+func (r *DB) PluckMapCtx(ctx context.Context, src any, colKey, colValue string) (val []map[any]any, err error) {
 	resource := reflect.ValueOf(src).Elem()
 	if err = validateFields(resource, src, []string{colKey, colValue}); err != nil {
 		return nil, err
 	}
 
-	res, err := r.eachToStructRows(src, r.Builder.offset, r.Builder.limit)
+	res, err := r.eachToStructRows(ctx, src, r.Builder.offset, r.Builder.limit)
 	if err != nil {
 		return nil, err
 	}
@@ -69,20 +96,29 @@ func (r *DB) PluckMap(src any, colKey, colValue string) (val []map[any]any, err
 
 // Exists checks whether conditional rows are existing (returns true) or not (returns false)
 func (r *DB) Exists() (exists bool, err error) {
-	bldr := r.Builder
-	if bldr.table == "" {
-		return false, errTableCallBeforeOp
+	return r.ExistsCtx(context.Background())
+}
+
+// ExistsCtx checks whether conditional rows are existing (returns true) or not (returns false) with the given context
+func (r *DB) ExistsCtx(ctx context.Context) (exists bool, err error) {
+	query, args, err := r.Builder.existsSQL()
+	if err != nil {
+		return false, err
 	}
 
-	query := `SELECT EXISTS(SELECT 1 FROM "` + bldr.table + `" ` + bldr.buildClauses() + `)`
-	err = r.Sql().QueryRow(query, prepareValues(r.Builder.whereBindings)...).Scan(&exists)
+	err = r.queryRow(ctx, query, args, &exists)
 
 	return
 }
 
 // DoesntExists an inverse of Exists
 func (r *DB) DoesntExists() (bool, error) {
-	ex, err := r.Exists()
+	return r.DoesntExistsCtx(context.Background())
+}
+
+// DoesntExistsCtx an inverse of ExistsCtx
+func (r *DB) DoesntExistsCtx(ctx context.Context) (bool, error) {
+	ex, err := r.ExistsCtx(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -92,24 +128,32 @@ func (r *DB) DoesntExists() (bool, error) {
 
 // Increment column on passed value
 func (r *DB) Increment(column string, on uint64) (int64, error) {
-	return r.incrDecr(column, plusSign, on)
+	return r.IncrementCtx(context.Background(), column, on)
+}
+
+// IncrementCtx column on passed value with the given context
+func (r *DB) IncrementCtx(ctx context.Context, column string, on uint64) (int64, error) {
+	return r.incrDecr(ctx, column, plusSign, on)
 }
 
 // Decrement column on passed value
 func (r *DB) Decrement(column string, on uint64) (int64, error) {
-	return r.incrDecr(column, minusSign, on)
+	return r.DecrementCtx(context.Background(), column, on)
+}
+
+// DecrementCtx column on passed value with the given context
+func (r *DB) DecrementCtx(ctx context.Context, column string, on uint64) (int64, error) {
+	return r.incrDecr(ctx, column, minusSign, on)
 }
 
 // increments or decrements depending on sign
-func (r *DB) incrDecr(column, sign string, on uint64) (int64, error) {
-	bldr := r.Builder
-	if bldr.table == "" {
-		return 0, errTableCallBeforeOp
+func (r *DB) incrDecr(ctx context.Context, column, sign string, on uint64) (int64, error) {
+	query, args, err := r.Builder.incrDecrSQL(column, sign, on)
+	if err != nil {
+		return 0, err
 	}
 
-	query := `UPDATE "` + r.Builder.table + `" SET ` + column + ` = ` + column + sign + strconv.FormatUint(on, 10)
-
-	res, err := r.Sql().Exec(query)
+	res, err := r.exec(ctx, OpUpdate, r.Builder.table, query, args...)
 	if err != nil {
 		return 0, err
 	}
@@ -120,19 +164,22 @@ func (r *DB) incrDecr(column, sign string, on uint64) (int64, error) {
 // Chunk run queries by chinks by passing user-land function with an ability to stop execution when needed
 // by returning false and proceed to execute queries when return true
 func (r *DB) Chunk(src any, amount int64, fn func(rows []any) bool) error {
-	cols := r.Builder.columns
-	cnt, err := r.Count()
+	return r.ChunkCtx(context.Background(), src, amount, fn)
+}
+
+// ChunkCtx run queries by chinks with the given context, which is checked between chunks as well
+func (r *DB) ChunkCtx(ctx context.Context, src any, amount int64, fn func(rows []any) bool) error {
+	cnt, err := r.CountCtx(ctx)
 	if err != nil {
 		return err
 	}
 
-	r.Builder.columns = cols
 	if amount <= 0 {
 		return fmt.Errorf("chunk can't be <= 0, your chunk is: %d", amount)
 	}
 
 	if cnt < amount {
-		structRows, err := r.eachToStructRows(src, 0, 0)
+		structRows, err := r.eachToStructRows(ctx, src, 0, 0)
 		if err != nil {
 			return err
 		}
@@ -145,7 +192,11 @@ func (r *DB) Chunk(src any, amount int64, fn func(rows []any) bool) error {
 	// executing chunks amount < cnt
 	c := int64(math.Ceil(float64(cnt / amount)))
 	for i := int64(0); i < c; i++ {
-		structRows, err := r.eachToStructRows(src, i*amount, amount)
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		structRows, err := r.eachToStructRows(ctx, src, i*amount, amount)
 		if err != nil {
 			return err
 		}
@@ -159,14 +210,15 @@ func (r *DB) Chunk(src any, amount int64, fn func(rows []any) bool) error {
 	return nil
 }
 
-func (r *DB) eachToStructRows(src any, offset, limit int64) ([]any, error) {
+func (r *DB) eachToStructRows(ctx context.Context, src any, offset, limit int64) ([]any, error) {
 	var structRows []any
-	if limit > 0 {
-		r.Offset(offset).Limit(limit)
+	q := r
+	if limit > 0 { // paginate the copy, so the query can be reused
+		q = r.Clone().Offset(offset).Limit(limit)
 	}
 
-	err := r.EachToStruct(func(rows *sql.Rows) error {
-		err := r.Next(rows, src)
+	err := q.EachToStructCtx(ctx, func(rows *sql.Rows) error {
+		err := q.Next(rows, src)
 		if err != nil {
 			return err
 		}
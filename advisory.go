@@ -0,0 +1,200 @@
+package buildsqlx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+)
+
+var errAdvisoryLockReleased = fmt.Errorf("sql: advisory lock has already been released")
+
+// LockKey identifies PostgreSQL advisory lock, keys are shared by all the sessions of the database
+type LockKey int64
+
+// StringLockKey builds LockKey from name hashing it with 64-bit FNV-1a, e.g. StringLockKey("cron:reports")
+func StringLockKey(name string) LockKey {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+
+	return LockKey(h.Sum64())
+}
+
+// AdvisoryLock is a session-level advisory lock held by a dedicated connection until Unlock,
+// so the pool can't hand the session to another query or release the lock on a different session
+type AdvisoryLock struct {
+	Key  LockKey
+	db   *DB
+	conn *sql.Conn
+}
+
+// Conn returns the dedicated connection holding the lock
+func (l *AdvisoryLock) Conn() *sql.Conn {
+	return l.conn
+}
+
+// Unlock releases the lock and returns the connection to the pool
+func (l *AdvisoryLock) Unlock() error {
+	return l.UnlockCtx(context.Background())
+}
+
+// UnlockCtx releases the lock with the given context and returns the connection to the pool,
+// if the lock can't be released the connection is discarded to end the session, which releases the lock as well
+func (l *AdvisoryLock) UnlockCtx(ctx context.Context) error {
+	if l.conn == nil {
+		return errAdvisoryLockReleased
+	}
+
+	conn := l.conn
+	l.conn = nil
+
+	released, err := l.db.advisoryQuery(ctx, conn, "pg_advisory_unlock", l.Key)
+	if err == nil && !released {
+		err = fmt.Errorf("sql: advisory lock %d wasn't held by the session", l.Key)
+	}
+
+	if err != nil {
+		_ = conn.Raw(func(any) error {
+			return driver.ErrBadConn
+		})
+	}
+
+	if errClose := conn.Close(); err == nil {
+		err = errClose
+	}
+
+	return err
+}
+
+// AdvisoryLock waits for session-level advisory lock on a dedicated connection, the lock must be released by Unlock
+func (r *DB) AdvisoryLock(key LockKey) (*AdvisoryLock, error) {
+	return r.AdvisoryLockCtx(context.Background(), key)
+}
+
+// AdvisoryLockCtx waits for session-level advisory lock on a dedicated connection with the given context,
+// the lock must be released by Unlock
+func (r *DB) AdvisoryLockCtx(ctx context.Context, key LockKey) (*AdvisoryLock, error) {
+	l, _, err := r.advisoryLock(ctx, "pg_advisory_lock", key)
+	return l, err
+}
+
+// TryAdvisoryLock acquires session-level advisory lock on a dedicated connection if it's available without waiting,
+// false is returned with nil lock if the lock is held by another session
+func (r *DB) TryAdvisoryLock(key LockKey) (*AdvisoryLock, bool, error) {
+	return r.TryAdvisoryLockCtx(context.Background(), key)
+}
+
+// TryAdvisoryLockCtx acquires session-level advisory lock on a dedicated connection with the given context
+// if it's available without waiting, false is returned with nil lock if the lock is held by another session
+func (r *DB) TryAdvisoryLockCtx(ctx context.Context, key LockKey) (*AdvisoryLock, bool, error) {
+	return r.advisoryLock(ctx, "pg_try_advisory_lock", key)
+}
+
+// WithAdvisoryLock runs fn holding session-level advisory lock, which is released after fn returns or panics
+func (r *DB) WithAdvisoryLock(key LockKey, fn func() error) error {
+	return r.WithAdvisoryLockCtx(context.Background(), key, fn)
+}
+
+// WithAdvisoryLockCtx runs fn holding session-level advisory lock waited for with the given context,
+// the lock is released after fn returns or panics even if the context is done
+func (r *DB) WithAdvisoryLockCtx(ctx context.Context, key LockKey, fn func() error) (err error) {
+	l, err := r.AdvisoryLockCtx(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if errUnlock := l.UnlockCtx(context.WithoutCancel(ctx)); errUnlock != nil {
+			err = errors.Join(err, errUnlock)
+		}
+	}()
+
+	return fn()
+}
+
+// AdvisoryXactLock waits for transaction-level advisory lock, which is released on commit or rollback
+func (r *DB) AdvisoryXactLock(key LockKey) error {
+	return r.AdvisoryXactLockCtx(context.Background(), key)
+}
+
+// AdvisoryXactLockCtx waits for transaction-level advisory lock with the given context,
+// which is released on commit or rollback
+func (r *DB) AdvisoryXactLockCtx(ctx context.Context, key LockKey) error {
+	if r.Txn == nil {
+		return errTransactionModeWithoutTx
+	}
+
+	_, err := r.advisoryQuery(ctx, r.Txn.Tx, "pg_advisory_xact_lock", key)
+	return err
+}
+
+// TryAdvisoryXactLock acquires transaction-level advisory lock if it's available without waiting,
+// false is returned if the lock is held by another session
+func (r *DB) TryAdvisoryXactLock(key LockKey) (bool, error) {
+	return r.TryAdvisoryXactLockCtx(context.Background(), key)
+}
+
+// TryAdvisoryXactLockCtx acquires transaction-level advisory lock with the given context
+// if it's available without waiting, false is returned if the lock is held by another session
+func (r *DB) TryAdvisoryXactLockCtx(ctx context.Context, key LockKey) (bool, error) {
+	if r.Txn == nil {
+		return false, errTransactionModeWithoutTx
+	}
+
+	return r.advisoryQuery(ctx, r.Txn.Tx, "pg_try_advisory_xact_lock", key)
+}
+
+// advisoryLock takes a dedicated connection from primary and acquires session-level lock on it,
+// the connection is returned to the pool if the lock hasn't been acquired
+func (r *DB) advisoryLock(ctx context.Context, fn string, key LockKey) (*AdvisoryLock, bool, error) {
+	if err := r.advisorySupported(); err != nil {
+		return nil, false, err
+	}
+
+	conn, err := r.Sql().Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	locked, err := r.advisoryQuery(ctx, conn, fn, key)
+	if err != nil || !locked {
+		_ = conn.Close()
+		return nil, false, err
+	}
+
+	return &AdvisoryLock{Key: key, db: r, conn: conn}, true, nil
+}
+
+// advisoryQuery calls advisory lock function reporting whether the lock has been acquired or released,
+// functions that wait for the lock return void, so they report true on success
+func (r *DB) advisoryQuery(ctx context.Context, q queryer, fn string, key LockKey) (bool, error) {
+	if err := r.advisorySupported(); err != nil {
+		return false, err
+	}
+
+	query := `SELECT ` + fn + `(` + r.Builder.dialect.Placeholder(1) + `)`
+	e := r.Builder.event(OpSelect, "", query, []any{int64(key)})
+	if r.Txn != nil && q == queryer(r.Txn.Tx) { // session-level locks run on a dedicated connection out of transaction
+		e.InTx = true
+		ctx = r.hookCtx(ctx)
+	}
+
+	var res any
+	if err := queryRowHooked(ctx, q, r.allHooks(), e, &res); err != nil {
+		return false, err
+	}
+
+	ok, isBool := res.(bool)
+	return ok || !isBool, nil
+}
+
+// advisorySupported checks whether advisory locks can be used with the dialect
+func (r *DB) advisorySupported() error {
+	if d := r.Builder.dialect; d.Name() != (Postgres{}).Name() {
+		return fmt.Errorf("sql: %s dialect doesn't support advisory locks", d.Name())
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package buildsqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringLockKey(t *testing.T) {
+	require.Equal(t, StringLockKey("cron:reports"), StringLockKey("cron:reports"))
+	require.NotEqual(t, StringLockKey("cron:reports"), StringLockKey("cron:cleanup"))
+	require.Equal(t, LockKey(-3750763034362895579), StringLockKey(""))
+}
+
+func TestDB_AdvisoryLock_Unsupported(t *testing.T) {
+	mysqlDb := NewDb(NewConnectionFromDb(&sql.DB{}), WithDialect(MySQL{}))
+
+	_, err := mysqlDb.AdvisoryLock(1)
+	require.EqualError(t, err, "sql: mysql dialect doesn't support advisory locks")
+
+	err = mysqlDb.AdvisoryXactLock(1)
+	require.Equal(t, errTransactionModeWithoutTx, err)
+}
+
+func TestDB_AdvisoryLock(t *testing.T) {
+	key := StringLockKey("buildsqlx:test")
+
+	l, err := db.AdvisoryLock(key)
+	require.NoError(t, err)
+
+	// the lock is held by the dedicated connection, so the other sessions can't take it
+	other, ok, err := db.TryAdvisoryLock(key)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Nil(t, other)
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	ok, err = tx.TryAdvisoryXactLock(key)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.NoError(t, tx.Rollback())
+
+	require.NoError(t, l.Unlock())
+	require.Equal(t, errAdvisoryLockReleased, l.Unlock())
+
+	// transaction-level lock is released on commit
+	tx, err = db.Begin()
+	require.NoError(t, err)
+	require.NoError(t, tx.AdvisoryXactLock(key))
+	_, ok, err = db.TryAdvisoryLock(key)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.NoError(t, tx.Commit())
+
+	l, ok, err = db.TryAdvisoryLock(key)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, l.UnlockCtx(context.Background()))
+
+	// the lock is released whether fn fails or panics
+	errFn := errors.New("job failed")
+	err = db.WithAdvisoryLock(key, func() error {
+		_, ok, err := db.TryAdvisoryLock(key)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		return errFn
+	})
+	require.Equal(t, errFn, err)
+
+	require.Panics(t, func() {
+		_ = db.WithAdvisoryLock(key, func() error {
+			panic("job panicked")
+		})
+	})
+
+	l, ok, err = db.TryAdvisoryLock(key)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, l.Unlock())
+}
@@ -1,51 +1,88 @@
 package buildsqlx
 
+import "context"
+
 // Count counts resulting rows based on clause
 func (r *DB) Count() (cnt int64, err error) {
-	bldr := r.Builder
-	bldr.columns = []string{"COUNT(*)"}
-	query := bldr.buildSelect()
-	err = r.Sql().QueryRow(query, prepareValues(r.Builder.whereBindings)...).Scan(&cnt)
+	return r.CountCtx(context.Background())
+}
+
+// CountCtx counts resulting rows based on clause with the given context
+func (r *DB) CountCtx(ctx context.Context) (cnt int64, err error) {
+	query, args, err := r.Builder.aggregateSQL("COUNT(*)")
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.queryRow(ctx, query, args, &cnt)
 
 	return
 }
 
 // Avg calculates average for specified column
 func (r *DB) Avg(column string) (avg float64, err error) {
-	bldr := r.Builder
-	bldr.columns = []string{"AVG(" + column + ")"}
-	query := bldr.buildSelect()
-	err = r.Sql().QueryRow(query, prepareValues(r.Builder.whereBindings)...).Scan(&avg)
+	return r.AvgCtx(context.Background(), column)
+}
+
+// AvgCtx calculates average for specified column with the given context
+func (r *DB) AvgCtx(ctx context.Context, column string) (avg float64, err error) {
+	query, args, err := r.Builder.aggregateSQL("AVG(" + column + ")")
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.queryRow(ctx, query, args, &avg)
 
 	return
 }
 
 // Min calculates minimum for specified column
 func (r *DB) Min(column string) (min float64, err error) {
-	bldr := r.Builder
-	bldr.columns = []string{"MIN(" + column + ")"}
-	query := bldr.buildSelect()
-	err = r.Sql().QueryRow(query, prepareValues(r.Builder.whereBindings)...).Scan(&min)
+	return r.MinCtx(context.Background(), column)
+}
+
+// MinCtx calculates minimum for specified column with the given context
+func (r *DB) MinCtx(ctx context.Context, column string) (min float64, err error) {
+	query, args, err := r.Builder.aggregateSQL("MIN(" + column + ")")
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.queryRow(ctx, query, args, &min)
 
 	return
 }
 
 // Max calculates maximum for specified column
 func (r *DB) Max(column string) (max float64, err error) {
-	bldr := r.Builder
-	bldr.columns = []string{"MAX(" + column + ")"}
-	query := bldr.buildSelect()
-	err = r.Sql().QueryRow(query, prepareValues(r.Builder.whereBindings)...).Scan(&max)
+	return r.MaxCtx(context.Background(), column)
+}
+
+// MaxCtx calculates maximum for specified column with the given context
+func (r *DB) MaxCtx(ctx context.Context, column string) (max float64, err error) {
+	query, args, err := r.Builder.aggregateSQL("MAX(" + column + ")")
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.queryRow(ctx, query, args, &max)
 
 	return
 }
 
 // Sum calculates sum for specified column
 func (r *DB) Sum(column string) (sum float64, err error) {
-	bldr := r.Builder
-	bldr.columns = []string{"SUM(" + column + ")"}
-	query := bldr.buildSelect()
-	err = r.Sql().QueryRow(query, prepareValues(r.Builder.whereBindings)...).Scan(&sum)
+	return r.SumCtx(context.Background(), column)
+}
+
+// SumCtx calculates sum for specified column with the given context
+func (r *DB) SumCtx(ctx context.Context, column string) (sum float64, err error) {
+	query, args, err := r.Builder.aggregateSQL("SUM(" + column + ")")
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.queryRow(ctx, query, args, &sum)
 
 	return
 }
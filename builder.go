@@ -1,23 +1,22 @@
 package buildsqlx
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
 	"log"
 	"os"
-	"strconv"
+	"strings"
 )
 
 const (
-	sqlKeyWordJoinInner = "INNER"
-	//JoinCross     = "CROSS"
+	sqlKeyWordJoinInner     = "INNER"
+	sqlKeyWordJoinCross     = "CROSS"
 	sqlKeyWordJoinLeft      = "LEFT"
 	sqlKeyWordJoinRight     = "RIGHT"
 	sqlKeyWordJoinFull      = "FULL"
 	sqlKeyWordJoinFullOuter = "FULL OUTER"
 	sqlKeyWordWhere         = " WHERE "
 	sqlKeyWordAnd           = " AND "
-	sqlKeyWordOr            = " OR "
 )
 
 const (
@@ -37,87 +36,192 @@ const (
 type builder struct {
 	whereBindings   []map[string]any
 	startBindingsAt int
-	where           string
 	table           string
 	from            string
-	join            []string
+	join            []joinClause
 	orderBy         []map[string]string
-	orderByRaw      *string
+	orderByRaw      *rawExpr
 	groupBy         string
-	having          string
+	havingBindings  []map[string]any
+	windows         []namedWindow
 	columns         []string
-	union           []string
+	selectRaw       *rawExpr
+	raw             *rawExpr
+	union           []*builder
 	isUnionAll      bool
 	offset          int64
 	limit           int64
 	lockForUpdate   *string
-	whereExists     string
+	selectSubs      []subQuery
+	fromSub         *subQuery
+	ctes            []cte
+	dialect         Dialect
 }
 
 // DB is an entity that composite builder and Conn types
 type DB struct {
-	Builder *builder
-	Conn    *Connection
-	Txn     *Txn
+	Builder   *builder
+	Conn      *Connection
+	Txn       *Txn
+	onPrimary bool
+	hooks     []Hook
 }
 
 type Txn struct {
 	Tx      *sql.Tx
 	Builder *builder
+	hooks   []Hook
+	ctx     context.Context
+	// txEvent is reported to hooks on Commit or Rollback of transaction started by Begin
+	txEvent *TxEvent
+	// depth is the number of savepoints created by nested InTransaction calls
+	depth         int
+	afterCommit   []func()
+	afterRollback []func()
 }
 
 func newBuilder() *builder {
 	return &builder{
-		columns: []string{"*"},
+		columns:         []string{"*"},
+		startBindingsAt: 1,
+		dialect:         Postgres{},
 	}
 }
 
+// clone returns a deep copy of builder, so the copy can be changed without affecting the original
+func (r *builder) clone() *builder {
+	b := *r
+	b.whereBindings = append([]map[string]any(nil), r.whereBindings...)
+	b.havingBindings = append([]map[string]any(nil), r.havingBindings...)
+	b.join = append([]joinClause(nil), r.join...)
+	b.orderBy = append([]map[string]string(nil), r.orderBy...)
+	b.columns = append([]string(nil), r.columns...)
+	b.union = append([]*builder(nil), r.union...)
+	b.selectSubs = append([]subQuery(nil), r.selectSubs...)
+	b.ctes = append([]cte(nil), r.ctes...)
+	b.windows = append([]namedWindow(nil), r.windows...)
+
+	return &b
+}
+
 // Sql returns DB struct
 func (r *DB) Sql() *sql.DB {
 	return r.Conn.db
 }
 
-// NewDb constructs default DB structure
-func NewDb(c *Connection) *DB {
-	b := newBuilder()
-	return &DB{Builder: b, Conn: c}
+// OnPrimary returns DB copy sending read queries to primary despite of replicas e.g. to read data that was just written
+func (r *DB) OnPrimary() *DB {
+	db := *r
+	db.onPrimary = true
+	return &db
 }
 
-// Table appends table name to sql query
-func (r *DB) Table(table string) *DB {
-	// reset before constructing again
-	r.reset()
-	r.Builder.table = table
+// reader returns handle for read queries - transaction in transaction mode,
+// primary if forced by OnPrimary or replica chosen by Connection policy for request tracked by ctx otherwise
+func (r *DB) reader(ctx context.Context) queryer {
+	if r.Txn != nil {
+		return r.Txn.Tx
+	}
+
+	if r.onPrimary {
+		return r.Conn.db
+	}
+
+	return r.Conn.replica(ctx)
+}
+
+// writer returns handle for write queries - transaction in transaction mode or primary otherwise
+func (r *DB) writer() queryer {
+	if r.Txn != nil {
+		return r.Txn.Tx
+	}
+
+	return r.Conn.db
+}
+
+// event returns QueryEvent for statement marked whether it's run in transaction
+func (r *DB) event(op Op, table, query string, args []any) *QueryEvent {
+	e := r.Builder.event(op, table, query, args)
+	e.InTx = r.Txn != nil
+
+	return e
+}
+
+// exec runs write query calling hooks around and tracking the write for sticky reads out of transaction
+func (r *DB) exec(ctx context.Context, op Op, table, query string, args ...any) (sql.Result, error) {
+	res, err := execHooked(r.hookCtx(ctx), r.writer(), r.allHooks(), r.event(op, table, query, args))
+	if err == nil && r.Txn == nil {
+		r.Conn.wrote(ctx)
+	}
+
+	return res, err
+}
+
+// queryRow runs read query on reader scanning a single row into dest and calling hooks around
+func (r *DB) queryRow(ctx context.Context, query string, args []any, dest ...any) error {
+	e := r.event(OpSelect, r.Builder.table, query, args)
+	return queryRowHooked(r.hookCtx(ctx), r.reader(ctx), r.allHooks(), e, dest...)
+}
+
+// event returns QueryEvent for statement run in transaction
+func (r *Txn) event(op Op, query string, args []any) *QueryEvent {
+	e := r.Builder.event(op, r.Builder.table, query, args)
+	e.InTx = true
+
+	return e
+}
+
+// exec runs write query in transaction calling hooks around
+func (r *Txn) exec(ctx context.Context, op Op, query string, args ...any) (sql.Result, error) {
+	return execHooked(r.hookCtx(ctx), r.Tx, r.hooks, r.event(op, query, args))
+}
+
+// NewDb constructs default DB structure, PostgreSQL dialect is used if there is no WithDialect option passed
+func NewDb(c *Connection, opts ...Option) *DB {
+	b := newBuilder()
+	r := &DB{Builder: b, Conn: c}
+	for _, opt := range opts {
+		opt(r)
+	}
+
 	return r
 }
 
-// resets all builder elements to prepare them for next round
-func (r *DB) reset() {
-	r.Builder.table = ""
-	r.Builder.columns = []string{"*"}
-	r.Builder.where = ""
-	r.Builder.whereBindings = make([]map[string]any, 0)
-	r.Builder.groupBy = ""
-	r.Builder.having = ""
-	r.Builder.orderBy = make([]map[string]string, 0)
-	r.Builder.offset = 0
-	r.Builder.limit = 0
-	r.Builder.join = []string{}
-	r.Builder.from = ""
-	r.Builder.lockForUpdate = nil
-	r.Builder.whereExists = ""
-	r.Builder.orderByRaw = nil
-	r.Builder.startBindingsAt = 1
-
-	if len(r.Builder.union) == 0 {
-		r.Builder.union = []string{}
+// Dialect returns the SQL dialect queries are built for
+func (r *DB) Dialect() Dialect {
+	return r.Builder.dialect
+}
+
+// Table starts a new query for table returning an independent value, so DB can be shared between goroutines,
+// queries glued by Union/UnionAll are carried over to the new query along with common table expressions of With
+// rendered once for the whole union, while a query without pending union starts with no expressions
+func (r *DB) Table(table string) *DB {
+	b := newBuilder()
+	b.dialect = r.Builder.dialect
+	b.union = append([]*builder(nil), r.Builder.union...)
+	b.isUnionAll = r.Builder.isUnionAll
+	if len(r.Builder.union) > 0 {
+		b.ctes = append([]cte(nil), r.Builder.ctes...)
 	}
+	b.table = table
+
+	db := *r
+	db.Builder = b
+	return &db
+}
+
+// Clone returns a copy of query, which can be branched e.g. with different conditions without affecting the original
+func (r *DB) Clone() *DB {
+	db := *r
+	db.Builder = r.Builder.clone()
+	return &db
 }
 
 // Select accepts columns to select from a table
 func (r *DB) Select(args ...string) *DB {
 	r.Builder.columns = []string{}
 	r.Builder.columns = append(r.Builder.columns, args...)
+	r.Builder.selectRaw = nil
 	return r
 }
 
@@ -127,15 +231,15 @@ func (r *DB) OrderBy(column string, direction string) *DB {
 	return r
 }
 
-// OrderByRaw adds ORDER BY raw expression to SQL stmt
-func (r *DB) OrderByRaw(exp string) *DB {
-	r.Builder.orderByRaw = &exp
+// OrderByRaw adds ORDER BY raw expression to SQL stmt with args bound to ? or $n placeholders if any
+func (r *DB) OrderByRaw(exp string, args ...any) *DB {
+	r.Builder.orderByRaw = newRawExpr(exp, args)
 	return r
 }
 
 // InRandomOrder add ORDER BY random() - note be cautious on big data-tables it can lead to slowing down perf
 func (r *DB) InRandomOrder() *DB {
-	r.OrderByRaw("random()")
+	r.OrderByRaw(r.Builder.dialect.Random())
 	return r
 }
 
@@ -147,25 +251,39 @@ func (r *DB) GroupBy(expr string) *DB {
 
 // Having similar to Where but used with GroupBy to apply over the grouped results
 func (r *DB) Having(operand, operator string, val any) *DB {
-	r.Builder.having = operand + " " + operator + " " + convertToStr(val)
-	return r
+	return r.buildHaving("", operand+" "+operator, conditionValue(operator, val))
 }
 
-// HavingRaw accepts custom string to apply it to having clause
-func (r *DB) HavingRaw(raw string) *DB {
-	r.Builder.having = raw
-	return r
+// AndHaving similar to AndWhere but used with GroupBy to apply over the grouped results
+func (r *DB) AndHaving(operand, operator string, val any) *DB {
+	return r.buildHaving(sqlOperatorAnd, operand+" "+operator, conditionValue(operator, val))
+}
+
+// OrHaving similar to OrWhere but used with GroupBy to apply over the grouped results
+func (r *DB) OrHaving(operand, operator string, val any) *DB {
+	return r.buildHaving(sqlOperatorOr, operand+" "+operator, conditionValue(operator, val))
+}
+
+// HavingRaw accepts custom string to apply it to having clause with args bound to ? or $n placeholders if any
+func (r *DB) HavingRaw(raw string, args ...any) *DB {
+	return r.buildHaving("", "", newRawExpr(raw, args))
 }
 
 // OrHavingRaw accepts custom string to apply it to having clause with logical OR
-func (r *DB) OrHavingRaw(raw string) *DB {
-	r.Builder.having += sqlKeyWordOr + raw
-	return r
+func (r *DB) OrHavingRaw(raw string, args ...any) *DB {
+	return r.buildHaving(sqlOperatorOr, "", newRawExpr(raw, args))
+}
+
+// AndHavingRaw accepts custom string to apply it to having clause with logical AND
+func (r *DB) AndHavingRaw(raw string, args ...any) *DB {
+	return r.buildHaving(sqlOperatorAnd, "", newRawExpr(raw, args))
 }
 
-// AndHavingRaw accepts custom string to apply it to having clause with logical OR
-func (r *DB) AndHavingRaw(raw string) *DB {
-	r.Builder.having += sqlKeyWordAnd + raw
+func (r *DB) buildHaving(prefix, condition string, val any) *DB {
+	if prefix != "" {
+		prefix = " " + prefix + " "
+	}
+	r.Builder.havingBindings = append(r.Builder.havingBindings, map[string]any{prefix + condition: val})
 	return r
 }
 
@@ -175,9 +293,11 @@ func (r *DB) AddSelect(args ...string) *DB {
 	return r
 }
 
-// SelectRaw accepts custom string to select from a table
-func (r *DB) SelectRaw(raw string) *DB {
-	r.Builder.columns = []string{raw}
+// SelectRaw accepts custom string to select from a table with args bound to ? or $n placeholders if any,
+// columns added by AddSelect are selected after it
+func (r *DB) SelectRaw(raw string, args ...any) *DB {
+	r.Builder.columns = []string{}
+	r.Builder.selectRaw = newRawExpr(raw, args)
 	return r
 }
 
@@ -196,11 +316,12 @@ func (r *DB) RightJoin(table, left, operator, right string) *DB {
 	return r.buildJoin(sqlKeyWordJoinRight, table, left+operator+right)
 }
 
-// CrossJoin joins tables by getting intersection of sets
-// todo: MySQL/PostgreSQL versions are different here impl their difference
-//func (r *DB) CrossJoin(table string, left string, operator string, right string) *DB {
-//	return r.buildJoin(JoinCross, table, left+operator+right)
-//}
+// CrossJoin joins tables by getting cartesian product of sets,
+// there is no ON condition as PostgreSQL doesn't support it, while MySQL treats CROSS JOIN ... ON as INNER JOIN
+func (r *DB) CrossJoin(table string) *DB {
+	r.Builder.join = append(r.Builder.join, joinClause{clause: " " + sqlKeyWordJoinCross + " JOIN " + table + " "})
+	return r
+}
 
 // FullJoin joins tables by getting all elements of both sets
 func (r *DB) FullJoin(table, left, operator, right string) *DB {
@@ -214,7 +335,10 @@ func (r *DB) FullOuterJoin(table, left, operator, right string) *DB {
 
 // Union joins multiple queries omitting duplicate records
 func (r *DB) Union() *DB {
-	r.Builder.union = append(r.Builder.union, r.Builder.buildSelect())
+	part := r.Builder.clone()
+	part.union = nil
+	part.ctes = nil
+	r.Builder.union = append(r.Builder.union, part)
 	return r
 }
 
@@ -225,20 +349,47 @@ func (r *DB) UnionAll() *DB {
 	return r
 }
 
-// WhereExists constructs one builder from another to implement WHERE EXISTS sql/dml clause
-func (r *DB) WhereExists(rr *DB) *DB {
-	r.Builder.whereExists = " WHERE EXISTS(" + rr.Builder.buildSelect() + ")"
+func (r *DB) buildJoin(joinType, table, on string) *DB {
+	r.Builder.join = append(r.Builder.join, joinClause{clause: " " + joinType + " JOIN " + table + " ON " + on + " "})
 	return r
 }
 
-// WhereNotExists constructs one builder from another to implement WHERE NOT EXISTS sql/dml clause
-func (r *DB) WhereNotExists(rr *DB) *DB {
-	r.Builder.whereExists = " WHERE NOT EXISTS(" + rr.Builder.buildSelect() + ")"
-	return r
+// joinClause is JOIN clause with ON conditions composed the same way as where clause
+type joinClause struct {
+	clause string
+	on     []map[string]any
 }
 
-func (r *DB) buildJoin(joinType, table, on string) *DB {
-	r.Builder.join = append(r.Builder.join, " "+joinType+" JOIN "+table+" ON "+on+" ")
+// InnerJoinOn joins tables by getting elements if found in both on conditions added by fn to j, ex.:
+// InnerJoinOn("posts", func(j *DB) { j.WhereColumn("users.id", "=", "posts.user_id").AndWhere("posts.status", "=", 1) })
+func (r *DB) InnerJoinOn(table string, fn func(j *DB)) *DB {
+	return r.buildJoinOn(sqlKeyWordJoinInner, table, fn)
+}
+
+// LeftJoinOn joins tables by getting elements from left without those that null on the right
+// on conditions added by fn to j
+func (r *DB) LeftJoinOn(table string, fn func(j *DB)) *DB {
+	return r.buildJoinOn(sqlKeyWordJoinLeft, table, fn)
+}
+
+// RightJoinOn joins tables by getting elements from right without those that null on the left
+// on conditions added by fn to j
+func (r *DB) RightJoinOn(table string, fn func(j *DB)) *DB {
+	return r.buildJoinOn(sqlKeyWordJoinRight, table, fn)
+}
+
+// FullJoinOn joins tables by getting all elements of both sets on conditions added by fn to j
+func (r *DB) FullJoinOn(table string, fn func(j *DB)) *DB {
+	return r.buildJoinOn(sqlKeyWordJoinFull, table, fn)
+}
+
+// FullOuterJoinOn joins tables by getting an outer sets on conditions added by fn to j
+func (r *DB) FullOuterJoinOn(table string, fn func(j *DB)) *DB {
+	return r.buildJoinOn(sqlKeyWordJoinFullOuter, table, fn)
+}
+
+func (r *DB) buildJoinOn(joinType, table string, fn func(j *DB)) *DB {
+	r.Builder.join = append(r.Builder.join, joinClause{clause: " " + joinType + " JOIN " + table + " ON ", on: r.conditions(fn)})
 	return r
 }
 
@@ -263,72 +414,148 @@ func (r *DB) buildWhere(prefix, operand, operator string, val any) *DB {
 	if prefix != "" {
 		prefix = " " + prefix + " "
 	}
-	r.Builder.whereBindings = append(r.Builder.whereBindings, map[string]any{prefix + operand + " " + operator: val})
+	r.Builder.whereBindings = append(r.Builder.whereBindings, map[string]any{prefix + operand + " " + operator: conditionValue(operator, val)})
+	return r
+}
+
+// whereGroup is a set of conditions wrapped into parentheses
+type whereGroup []map[string]any
+
+// WhereGroup appends conditions added by fn to q wrapped into parentheses to where clause, ex.:
+// WhereGroup(func(q *DB) { q.Where("a", "=", 1).OrWhere("b", "=", 2) }) for WHERE (a = $1 OR b = $2)
+func (r *DB) WhereGroup(fn func(q *DB)) *DB {
+	return r.buildWhereGroup("", fn)
+}
+
+// AndWhereGroup appends conditions added by fn to q wrapped into parentheses to where clause
+// with AND logical operator
+func (r *DB) AndWhereGroup(fn func(q *DB)) *DB {
+	return r.buildWhereGroup(sqlOperatorAnd, fn)
+}
+
+// OrWhereGroup appends conditions added by fn to q wrapped into parentheses to where clause
+// with OR logical operator
+func (r *DB) OrWhereGroup(fn func(q *DB)) *DB {
+	return r.buildWhereGroup(sqlOperatorOr, fn)
+}
+
+func (r *DB) buildWhereGroup(prefix string, fn func(q *DB)) *DB {
+	conditions := r.conditions(fn)
+	if len(conditions) == 0 {
+		return r
+	}
+
+	if prefix != "" {
+		prefix = " " + prefix + " "
+	}
+	r.Builder.whereBindings = append(r.Builder.whereBindings, map[string]any{prefix: whereGroup(conditions)})
 	return r
 }
 
+// conditions collects conditions of fn on a blank query, only where conditions of the query are taken
+func (r *DB) conditions(fn func(q *DB)) []map[string]any {
+	b := newBuilder()
+	b.dialect = r.Builder.dialect
+	fn(&DB{Builder: b, Conn: r.Conn})
+
+	return b.whereBindings
+}
+
+// whereColumn is a quoted column compared with operand instead of bound value
+type whereColumn string
+
+// between is a pair of values bound to BETWEEN condition
+type between [2]any
+
+// nullCheck is NULL or NOT NULL inlined into IS condition instead of bound value
+type nullCheck string
+
+// conditionValue wraps NULL or NOT NULL compared by IS operator into nullCheck, other values are bound as is
+func conditionValue(operator string, val any) any {
+	if s, ok := val.(string); ok && (operator == sqlOperatorIs || operator == sqlOperatorIs+" NOT") {
+		if u := strings.ToUpper(s); u == sqlSpecificValueNull || u == sqlSpecificValueNotNull {
+			return nullCheck(u)
+		}
+	}
+
+	return val
+}
+
+// WhereColumn accepts 2 columns and operator between them to compare the columns in where clause, ex.:
+// WhereColumn("updated_at", ">", "created_at"), columns are quoted including table qualified ones e.g. users.id
+func (r *DB) WhereColumn(left, operator, right string) *DB {
+	return r.buildWhereColumn("", left, operator, right)
+}
+
+// AndWhereColumn accepts 2 columns and operator between them to compare the columns in where clause
+// with AND logical operator
+func (r *DB) AndWhereColumn(left, operator, right string) *DB {
+	return r.buildWhereColumn(sqlOperatorAnd, left, operator, right)
+}
+
+// OrWhereColumn accepts 2 columns and operator between them to compare the columns in where clause
+// with OR logical operator
+func (r *DB) OrWhereColumn(left, operator, right string) *DB {
+	return r.buildWhereColumn(sqlOperatorOr, left, operator, right)
+}
+
+func (r *DB) buildWhereColumn(prefix, left, operator, right string) *DB {
+	d := r.Builder.dialect
+	return r.buildWhere(prefix, quoteIdent(d, left), operator, whereColumn(quoteIdent(d, right)))
+}
+
 // WhereBetween sets the clause BETWEEN 2 values
 func (r *DB) WhereBetween(col string, val1, val2 any) *DB {
-	return r.buildWhere("", col, sqlOperatorBetween, convertToStr(val1)+sqlKeyWordAnd+convertToStr(val2))
+	return r.buildWhere("", col, sqlOperatorBetween, between{val1, val2})
 }
 
 // OrWhereBetween sets the clause OR BETWEEN 2 values
 func (r *DB) OrWhereBetween(col string, val1, val2 any) *DB {
-	return r.buildWhere(sqlOperatorOr, col, sqlOperatorBetween, convertToStr(val1)+sqlKeyWordAnd+convertToStr(val2))
+	return r.buildWhere(sqlOperatorOr, col, sqlOperatorBetween, between{val1, val2})
 }
 
 // AndWhereBetween sets the clause AND BETWEEN 2 values
 func (r *DB) AndWhereBetween(col string, val1, val2 any) *DB {
-	return r.buildWhere(sqlOperatorAnd, col, sqlOperatorBetween, convertToStr(val1)+sqlKeyWordAnd+convertToStr(val2))
+	return r.buildWhere(sqlOperatorAnd, col, sqlOperatorBetween, between{val1, val2})
 }
 
 // WhereNotBetween sets the clause NOT BETWEEN 2 values
 func (r *DB) WhereNotBetween(col string, val1, val2 any) *DB {
-	return r.buildWhere("", col, sqlOperatorNotBetween, convertToStr(val1)+sqlKeyWordAnd+convertToStr(val2))
+	return r.buildWhere("", col, sqlOperatorNotBetween, between{val1, val2})
 }
 
 // OrWhereNotBetween sets the clause OR BETWEEN 2 values
 func (r *DB) OrWhereNotBetween(col string, val1, val2 any) *DB {
-	return r.buildWhere(sqlOperatorOr, col, sqlOperatorNotBetween, convertToStr(val1)+sqlKeyWordAnd+convertToStr(val2))
+	return r.buildWhere(sqlOperatorOr, col, sqlOperatorNotBetween, between{val1, val2})
 }
 
 // AndWhereNotBetween sets the clause AND BETWEEN 2 values
 func (r *DB) AndWhereNotBetween(col string, val1, val2 any) *DB {
-	return r.buildWhere(sqlOperatorAnd, col, sqlOperatorNotBetween, convertToStr(val1)+sqlKeyWordAnd+convertToStr(val2))
-}
-
-func convertToStr(val any) string {
-	switch v := val.(type) {
-	case string:
-		return "'" + v + "'"
-	case int:
-		return strconv.Itoa(v)
-	case int64:
-		return strconv.FormatInt(v, 10)
-	case uint64:
-		return strconv.FormatUint(v, 10)
-	case float64:
-		return fmt.Sprintf("%g", v)
-	}
-
-	return ""
+	return r.buildWhere(sqlOperatorAnd, col, sqlOperatorNotBetween, between{val1, val2})
 }
 
-// WhereRaw accepts custom string to apply it to where clause
-func (r *DB) WhereRaw(raw string) *DB {
-	r.Builder.where = sqlKeyWordWhere + raw
-	return r
+// WhereRaw accepts custom string to apply it to where clause with args bound to ? or $n placeholders if any,
+// which are renumbered to follow other conditions e.g. Where("a", "=", 1).AndWhereRaw("b > ? OR c < $1", 2)
+// for WHERE a = $1 AND b > $2 OR c < $3
+func (r *DB) WhereRaw(raw string, args ...any) *DB {
+	return r.buildWhereRaw("", raw, args)
 }
 
 // OrWhereRaw accepts custom string to apply it to where clause with logical OR
-func (r *DB) OrWhereRaw(raw string) *DB {
-	r.Builder.where += sqlKeyWordOr + raw
-	return r
+func (r *DB) OrWhereRaw(raw string, args ...any) *DB {
+	return r.buildWhereRaw(sqlOperatorOr, raw, args)
+}
+
+// AndWhereRaw accepts custom string to apply it to where clause with logical AND
+func (r *DB) AndWhereRaw(raw string, args ...any) *DB {
+	return r.buildWhereRaw(sqlOperatorAnd, raw, args)
 }
 
-// AndWhereRaw accepts custom string to apply it to where clause with logical OR
-func (r *DB) AndWhereRaw(raw string) *DB {
-	r.Builder.where += sqlKeyWordAnd + raw
+func (r *DB) buildWhereRaw(prefix, raw string, args []any) *DB {
+	if prefix != "" {
+		prefix = " " + prefix + " "
+	}
+	r.Builder.whereBindings = append(r.Builder.whereBindings, map[string]any{prefix: newRawExpr(raw, args)})
 	return r
 }
 
@@ -346,18 +573,44 @@ func (r *DB) Limit(lim int64) *DB {
 
 // Drop drops >=1 tables
 func (r *DB) Drop(tables string) (sql.Result, error) {
-	return r.Sql().Exec("DROP TABLE " + tables)
+	return r.DropCtx(context.Background(), tables)
+}
+
+// DropCtx drops >=1 tables with the given context
+func (r *DB) DropCtx(ctx context.Context, tables string) (sql.Result, error) {
+	return r.exec(ctx, OpDDL, tables, "DROP TABLE "+tables)
 }
 
 // Truncate clears >=1 tables
 func (r *DB) Truncate(tables string) (sql.Result, error) {
-	return r.Sql().Exec("TRUNCATE " + tables)
+	return r.TruncateCtx(context.Background(), tables)
+}
+
+// TruncateCtx clears >=1 tables with the given context
+func (r *DB) TruncateCtx(ctx context.Context, tables string) (res sql.Result, err error) {
+	if !r.Builder.dialect.SchemaGrammar().Truncate { // emulate TRUNCATE by deleting all rows table by table
+		for _, tbl := range strings.Split(tables, ",") {
+			res, err = r.exec(ctx, OpDelete, strings.TrimSpace(tbl), "DELETE FROM "+strings.TrimSpace(tbl))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return res, nil
+	}
+
+	return r.exec(ctx, OpDDL, tables, "TRUNCATE "+tables)
 }
 
 // DropIfExists drops >=1 tables if they are existent
 func (r *DB) DropIfExists(tables ...string) (res sql.Result, err error) {
+	return r.DropIfExistsCtx(context.Background(), tables...)
+}
+
+// DropIfExistsCtx drops >=1 tables if they are existent with the given context
+func (r *DB) DropIfExistsCtx(ctx context.Context, tables ...string) (res sql.Result, err error) {
 	for _, tbl := range tables {
-		res, err = r.Sql().Exec("DROP TABLE" + IfExistsExp + tbl)
+		res, err = r.exec(ctx, OpDDL, tbl, "DROP TABLE"+IfExistsExp+tbl)
 	}
 
 	return res, err
@@ -365,7 +618,12 @@ func (r *DB) DropIfExists(tables ...string) (res sql.Result, err error) {
 
 // Rename renames from - to new table name
 func (r *DB) Rename(from, to string) (sql.Result, error) {
-	return r.Sql().Exec("ALTER TABLE " + from + " RENAME TO " + to)
+	return r.RenameCtx(context.Background(), from, to)
+}
+
+// RenameCtx renames from - to new table name with the given context
+func (r *DB) RenameCtx(ctx context.Context, from, to string) (sql.Result, error) {
+	return r.exec(ctx, OpDDL, from, "ALTER TABLE "+from+" RENAME TO "+to)
 }
 
 // WhereIn appends IN (val1, val2, val3...) stmt to WHERE clause
@@ -437,32 +695,32 @@ func (r *DB) AndWhereNotIn(field string, in any) *DB {
 
 // WhereNull appends fieldName IS NULL stmt to WHERE clause
 func (r *DB) WhereNull(field string) *DB {
-	return r.buildWhere("", field, sqlOperatorIs, sqlSpecificValueNull)
+	return r.buildWhere("", field, sqlOperatorIs, nullCheck(sqlSpecificValueNull))
 }
 
 // WhereNotNull appends fieldName IS NOT NULL stmt to WHERE clause
 func (r *DB) WhereNotNull(field string) *DB {
-	return r.buildWhere("", field, sqlOperatorIs, sqlSpecificValueNotNull)
+	return r.buildWhere("", field, sqlOperatorIs, nullCheck(sqlSpecificValueNotNull))
 }
 
 // OrWhereNull appends fieldName IS NULL stmt to WHERE clause
 func (r *DB) OrWhereNull(field string) *DB {
-	return r.buildWhere(sqlOperatorOr, field, sqlOperatorIs, sqlSpecificValueNull)
+	return r.buildWhere(sqlOperatorOr, field, sqlOperatorIs, nullCheck(sqlSpecificValueNull))
 }
 
 // OrWhereNotNull appends fieldName IS NOT NULL stmt to WHERE clause
 func (r *DB) OrWhereNotNull(field string) *DB {
-	return r.buildWhere(sqlOperatorOr, field, sqlOperatorIs, sqlSpecificValueNotNull)
+	return r.buildWhere(sqlOperatorOr, field, sqlOperatorIs, nullCheck(sqlSpecificValueNotNull))
 }
 
 // AndWhereNull appends fieldName IS NULL stmt to WHERE clause
 func (r *DB) AndWhereNull(field string) *DB {
-	return r.buildWhere(sqlOperatorAnd, field, sqlOperatorIs, sqlSpecificValueNull)
+	return r.buildWhere(sqlOperatorAnd, field, sqlOperatorIs, nullCheck(sqlSpecificValueNull))
 }
 
 // AndWhereNotNull appends fieldName IS NOT NULL stmt to WHERE clause
 func (r *DB) AndWhereNotNull(field string) *DB {
-	return r.buildWhere(sqlOperatorAnd, field, sqlOperatorIs, sqlSpecificValueNotNull)
+	return r.buildWhere(sqlOperatorAnd, field, sqlOperatorIs, nullCheck(sqlSpecificValueNotNull))
 }
 
 // From prepares sql stmt to set data from another table, ex.:
@@ -474,18 +732,23 @@ func (r *DB) From(fromTbl string) *DB {
 
 // LockForUpdate locks table/row
 func (r *DB) LockForUpdate() *DB {
-	str := " FOR UPDATE"
+	str := r.Builder.dialect.LockForUpdate()
 	r.Builder.lockForUpdate = &str
 	return r
 }
 
-// Dump prints raw sql to stdout
+// Dump prints raw sql with args to stdout
+//
+// Deprecated: use ToSQL to get the SQL with args or WithLogger hook to log every executed statement with its duration.
 func (r *DB) Dump() {
+	query, args, _ := r.ToSQL()
 	log.SetOutput(os.Stdout)
-	log.Println(r.Builder.buildSelect())
+	log.Println(query, args)
 }
 
 // Dd prints raw sql to stdout and exit
+//
+// Deprecated: use WithLogger or NewLogger hook, as Dd exits the process.
 func (r *DB) Dd() {
 	r.Dump()
 	os.Exit(0)
@@ -493,18 +756,26 @@ func (r *DB) Dd() {
 
 // HasTable determines whether table exists in particular schema
 func (r *DB) HasTable(schema, tbl string) (tblExists bool, err error) {
-	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM pg_tables WHERE  schemaname = '%s' AND tablename = '%s')", schema, tbl)
-	err = r.Sql().QueryRow(query).Scan(&tblExists)
+	return r.HasTableCtx(context.Background(), schema, tbl)
+}
+
+// HasTableCtx determines whether table exists in particular schema with the given context
+func (r *DB) HasTableCtx(ctx context.Context, schema, tbl string) (tblExists bool, err error) {
+	query, args := r.Builder.dialect.HasTableQuery(schema, tbl)
+	err = queryRowHooked(r.hookCtx(ctx), r.writer(), r.allHooks(), r.event(OpSelect, tbl, query, args), &tblExists)
 	return
 }
 
 // HasColumns checks whether those cols exists in a particular schema/table
 func (r *DB) HasColumns(schema, tbl string, cols ...string) (colsExists bool, err error) {
-	andColumns := ""
+	return r.HasColumnsCtx(context.Background(), schema, tbl, cols...)
+}
+
+// HasColumnsCtx checks whether those cols exists in a particular schema/table with the given context
+func (r *DB) HasColumnsCtx(ctx context.Context, schema, tbl string, cols ...string) (colsExists bool, err error) {
 	for _, v := range cols { // todo: find a way to check columns in 1 query
-		andColumns = " AND column_name = '" + v + "'"
-		query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema='%s' AND table_name='%s'"+andColumns+")", schema, tbl)
-		err = r.Sql().QueryRow(query).Scan(&colsExists)
+		query, args := r.Builder.dialect.HasColumnQuery(schema, tbl, v)
+		err = queryRowHooked(r.hookCtx(ctx), r.writer(), r.allHooks(), r.event(OpSelect, tbl, query, args), &colsExists)
 
 		if !colsExists { // if at least once col doesn't exist - return false, nil
 			return
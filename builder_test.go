@@ -1,10 +1,13 @@
 package buildsqlx
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -671,6 +674,54 @@ func TestDB_WhereNotExists(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestDB_AndWhereExists(t *testing.T) {
+	_, err := db.Truncate(UsersTable)
+	require.NoError(t, err)
+
+	err = db.Table(UsersTable).InsertBatch(batchUsers)
+	require.NoError(t, err)
+
+	dataStruct := &DataStructUser{}
+	er := db.Table(UsersTable).Select("name").Where("points", ">", int64(1234)).AndWhereExists(
+		db.Table(UsersTable).Select("name").Where("points", ">=", int64(12345)),
+	).First(dataStruct)
+	require.NoError(t, er)
+	require.Equal(t, TestUserName, dataStruct.Name)
+
+	exists, er := db.Table(UsersTable).WhereExists(
+		db.Table(UsersTable).Select("name").Where("points", ">", int64(12345)),
+	).Exists()
+	require.NoError(t, er)
+	require.False(t, exists)
+
+	_, err = db.Truncate(UsersTable)
+	require.NoError(t, err)
+}
+
+func TestDB_AndWhereNotExists(t *testing.T) {
+	_, err := db.Truncate(UsersTable)
+	require.NoError(t, err)
+
+	err = db.Table(UsersTable).InsertBatch(batchUsers)
+	require.NoError(t, err)
+
+	dataStruct := &DataStructUser{}
+	er := db.Table(UsersTable).Select("name").Where("points", ">", int64(1234)).AndWhereNotExists(
+		db.Table(UsersTable).Select("name").Where("points", ">", int64(12345)),
+	).First(dataStruct)
+	require.NoError(t, er)
+	require.Equal(t, TestUserName, dataStruct.Name)
+
+	exists, er := db.Table(UsersTable).WhereNotExists(
+		db.Table(UsersTable).Select("name").Where("points", ">=", int64(12345)),
+	).Exists()
+	require.NoError(t, er)
+	require.False(t, exists)
+
+	_, err = db.Truncate(UsersTable)
+	require.NoError(t, err)
+}
+
 func TestDB_Value(t *testing.T) {
 	_, err := db.Truncate(UsersTable)
 	require.NoError(t, err)
@@ -1047,6 +1098,89 @@ func TestDB_SelectRaw(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestDB_HavingBindings(t *testing.T) {
+	q := db.Table(UsersTable).Select("name", "SUM(points)").Where("points", ">", 0).GroupBy("name").
+		Having("SUM(points)", ">=", 100).AndHaving("COUNT(*)", "<", int32(3)).OrHaving("name", "=", "it's").
+		OrHavingRaw("MAX(points) = 0").OrderBy("name", "ASC")
+
+	query, args, err := q.ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT name, SUM(points) FROM "test_users" WHERE points > $1 GROUP BY name `+
+		`HAVING SUM(points) >= $2 AND COUNT(*) < $3 OR name = $4 OR MAX(points) = 0 ORDER BY name ASC`, query)
+	require.Equal(t, []any{0, 100, int32(3), "it's"}, args)
+
+	query, args, err = q.CountSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT COUNT(*) FROM "test_users" WHERE points > $1 GROUP BY name `+
+		`HAVING SUM(points) >= $2 AND COUNT(*) < $3 OR name = $4 OR MAX(points) = 0 ORDER BY name ASC`, query)
+	require.Equal(t, []any{0, 100, int32(3), "it's"}, args)
+
+	// conditions containing IS or BETWEEN are bound as any other ones, only NULL checks are inlined
+	query, args, err = db.Table(UsersTable).Select("name").Where("IS_ACTIVE", "=", true).
+		AndWhere("BETWEEN_DATES", ">", 1).AndWhereNull("DISTRICT").OrWhere("deleted_at", "IS", "not null").
+		GroupBy("name").Having("COUNT(DISTINCT id)", ">", 1).AndHaving("MAX(IS_ACTIVE)", "=", true).ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT name FROM "test_users" WHERE IS_ACTIVE = $1 AND BETWEEN_DATES > $2 AND DISTRICT IS NULL `+
+		`OR deleted_at IS NOT NULL GROUP BY name HAVING COUNT(DISTINCT id) > $3 AND MAX(IS_ACTIVE) = $4`, query)
+	require.Equal(t, []any{true, 1, 1, true}, args)
+
+	type flagged struct {
+		Active bool `db:"IS_ACTIVE"`
+		Name   string
+	}
+	query, args, err = db.Table(UsersTable).InsertSQL(flagged{Active: true, Name: "Alex"})
+	require.NoError(t, err)
+	require.Equal(t, `INSERT INTO "test_users" (IS_ACTIVE, name) VALUES($1, $2)`, query)
+	require.Equal(t, []any{true, "Alex"}, args)
+}
+
+func TestDB_AndOrHaving(t *testing.T) {
+	_, err := db.Truncate(UsersTable)
+	require.NoError(t, err)
+
+	err = db.Table(UsersTable).InsertBatch(batchUsers)
+	require.NoError(t, err)
+
+	var names []string
+	dataStruct := &DataStructUser{}
+	err = db.Table(UsersTable).Select("name").GroupBy("name").Having("COUNT(*)", ">", 1).
+		OrHaving("SUM(points)", "<", 200).AndHaving("MIN(points)", ">", int32(0)).OrderBy("name", "ASC").
+		EachToStruct(func(rows *sql.Rows) error {
+			err = db.Next(rows, dataStruct)
+			if err == nil {
+				names = append(names, dataStruct.Name)
+			}
+
+			return err
+		})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Alex Shmidt", TestUserName}, names)
+
+	_, err = db.Truncate(UsersTable)
+	require.NoError(t, err)
+}
+
+func TestDB_WhereBetweenBindings(t *testing.T) {
+	from, to := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	query, args, err := db.Table(UsersTable).WhereBetween("points", 1, 10).OrWhereNotBetween("created_at", from, to).
+		AndWhereBetween("name", "a'b", "c").AndWhereNotBetween("rate", float32(0.5), 1.5).ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT * FROM "test_users" WHERE points BETWEEN $1 AND $2 OR created_at NOT BETWEEN $3 AND $4 `+
+		`AND name BETWEEN $5 AND $6 AND rate NOT BETWEEN $7 AND $8`, query)
+	require.Equal(t, []any{1, 10, from, to, "a'b", "c", float32(0.5), 1.5}, args)
+
+	type flags struct {
+		Name   string
+		Active bool
+		Rate   float32
+		Level  uint8
+	}
+	query, args, err = db.Table(UsersTable).InsertSQL(flags{Name: "Alex", Active: true, Rate: 1.1, Level: 2})
+	require.NoError(t, err)
+	require.Equal(t, `INSERT INTO "test_users" (name, active, rate, level) VALUES($1, $2, $3, $4)`, query)
+	require.Equal(t, []any{"Alex", true, "1.1", "2"}, args)
+}
+
 func TestDB_AndWhereBetween(t *testing.T) {
 	_, err := db.Truncate(UsersTable)
 	require.NoError(t, err)
@@ -1691,3 +1825,416 @@ func TestDB_ScanStruct(t *testing.T) {
 	_, err = db.Truncate(TestTable)
 	require.NoError(t, err)
 }
+
+func TestDB_Ctx(t *testing.T) {
+	ctx := context.Background()
+	_, err := db.TruncateCtx(ctx, TestTable)
+	require.NoError(t, err)
+
+	err = db.Table(TestTable).InsertCtx(ctx, data)
+	require.NoError(t, err)
+
+	cnt, err := db.Table(TestTable).CountCtx(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), cnt)
+
+	dataStruct := &DataStruct{}
+	err = db.Table(TestTable).Select("foo", "bar", "baz").ScanStructCtx(ctx, dataStruct)
+	require.NoError(t, err)
+	require.Equal(t, data, *dataStruct)
+
+	err = db.InTransactionCtx(ctx, func() (any, error) {
+		return db.Table(TestTable).Where("foo", "=", data.Foo).UpdateCtx(ctx, DataStruct{Foo: "foo ctx", Bar: "bar ctx"})
+	})
+	require.NoError(t, err)
+
+	exists, err := db.Table(TestTable).Where("foo", "=", "foo ctx").ExistsCtx(ctx)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	err = db.Table(TestTable).Select("foo", "bar", "baz").ScanStructCtx(cancelledCtx, dataStruct)
+	require.True(t, errors.Is(err, context.Canceled))
+
+	_, err = db.Table(TestTable).DeleteCtx(cancelledCtx)
+	require.True(t, errors.Is(err, context.Canceled))
+
+	err = db.InTransactionCtx(cancelledCtx, func() (any, error) {
+		return 1, nil
+	})
+	require.True(t, errors.Is(err, context.Canceled))
+
+	_, err = db.TruncateCtx(ctx, TestTable)
+	require.NoError(t, err)
+}
+
+func TestDB_Clone(t *testing.T) {
+	base := db.Table(UsersTable).Select("name").Where("points", ">", 1)
+	byId := base.Clone().AndWhere("id", "=", 2).OrderBy("id", "DESC")
+	limited := base.Clone().Limit(3)
+
+	require.Equal(t, `SELECT name FROM "test_users" WHERE points > $1`, base.Builder.buildSelect())
+	require.Equal(t, `SELECT name FROM "test_users" WHERE points > $1 AND id = $2 ORDER BY id DESC`, byId.Builder.buildSelect())
+	require.Equal(t, `SELECT name FROM "test_users" WHERE points > $1 LIMIT 3`, limited.Builder.buildSelect())
+	require.Equal(t, []any{1}, prepareValues(base.Builder.whereBindings))
+	require.Equal(t, []any{1, 2}, prepareValues(byId.Builder.whereBindings))
+
+	// every Table call starts an independent query carrying over pending union only
+	union := base.Union()
+	users := union.Table(UsersTable).Select("name")
+	require.Equal(t, `SELECT name FROM "test_users" WHERE points > $1 UNION SELECT name FROM "test_users"`,
+		users.Builder.buildQuery())
+	require.Equal(t, `SELECT * FROM "test_posts"`, db.Table(PostsTable).Builder.buildQuery())
+
+	// Value and Find select the column and add the id condition to the copy
+	user := &User{}
+	_ = base.Value(user, "points")
+	_ = base.Find(user, 2)
+	require.Equal(t, `SELECT name FROM "test_users" WHERE points > $1`, base.Builder.buildSelect())
+	require.Equal(t, []any{1}, prepareValues(base.Builder.whereBindings))
+}
+
+func TestDB_ConcurrentBuild(t *testing.T) {
+	base := db.Table(UsersTable).Where("points", ">", 1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			q := db.Table(PostsTable).Select("title").Where("user_id", "=", i).Limit(int64(i + 1))
+			require.Equal(t, `SELECT title FROM "test_posts" WHERE user_id = $1 LIMIT `+strconv.Itoa(i+1), q.Builder.buildSelect())
+			require.Equal(t, []any{i}, prepareValues(q.Builder.whereBindings))
+
+			b := base.Clone().AndWhere("id", "=", i)
+			require.Equal(t, `SELECT * FROM "test_users" WHERE points > $1 AND id = $2`, b.Builder.buildSelect())
+			require.Equal(t, []any{1, i}, prepareValues(b.Builder.whereBindings))
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestDB_ConcurrentQueries(t *testing.T) {
+	_, err := db.Truncate(UsersTable)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			err := db.Table(UsersTable).Insert(User{ID: int64(i + 1), Name: "user " + strconv.Itoa(i), Points: int64(i)})
+			require.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	base := db.Table(UsersTable).Select("name", "points").Where("points", ">=", 10)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			cnt, err := base.Clone().AndWhere("points", "<", 15).Count()
+			require.NoError(t, err)
+			require.Equal(t, int64(5), cnt)
+
+			user := &User{}
+			err = db.Table(UsersTable).Select("name", "points").Where("points", "=", i).ScanStruct(user)
+			require.NoError(t, err)
+			require.Equal(t, int64(i), user.Points)
+
+			found := &User{}
+			err = base.Find(found, uint64(i+1))
+			if i >= 10 {
+				require.NoError(t, err)
+				require.Equal(t, user.Name, found.Name)
+			}
+
+			err = base.Value(found, "points")
+			require.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	// neither ScanStruct nor Count leaks limit or columns into the query
+	user := &User{}
+	err = base.ScanStruct(user)
+	require.NoError(t, err)
+	cnt, err := base.Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(10), cnt)
+
+	var names []string
+	err = base.EachToStruct(func(rows *sql.Rows) error {
+		err := base.Next(rows, user)
+		if err == nil {
+			names = append(names, user.Name)
+		}
+
+		return err
+	})
+	require.NoError(t, err)
+	require.Len(t, names, 10)
+
+	_, err = db.Truncate(UsersTable)
+	require.NoError(t, err)
+}
+
+func TestDB_ConcurrentTransactions(t *testing.T) {
+	_, err := db.Truncate(UsersTable)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+
+			_, err := InTx(ctx, db, func(tx *DB) (uint64, error) {
+				require.NotNil(t, tx.Txn)
+				return tx.Table(UsersTable).InsertGetId(User{ID: int64(i + 1), Name: "user " + strconv.Itoa(i), Points: int64(i)})
+			})
+			require.NoError(t, err)
+		}(i)
+
+		// queries of the shared db never run in transactions of other goroutines
+		go func() {
+			defer wg.Done()
+
+			q := db.Table(UsersTable)
+			require.Nil(t, q.Txn)
+			_, err := q.Count()
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	require.Nil(t, db.Txn)
+
+	cnt, err := db.Table(UsersTable).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(20), cnt)
+
+	_, err = db.Truncate(UsersTable)
+	require.NoError(t, err)
+}
+
+func TestDB_WhereGroup(t *testing.T) {
+	q := db.Table(UsersTable).Where("points", ">", 1).AndWhereGroup(func(q *DB) {
+		q.Where("name", "=", "Alex").OrWhereIn("id", []int64{2, 3}).OrWhereGroup(func(q *DB) {
+			q.WhereNull("name").AndWhere("points", "<", 100)
+		})
+	}).OrWhere("id", "=", 4)
+
+	query, args, err := q.ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT * FROM "test_users" WHERE points > $1 AND (name = $2 OR id IN ($3, $4) OR (name IS NULL AND points < $5)) OR id = $6`, query)
+	require.Equal(t, []any{1, "Alex", int64(2), int64(3), 100, 4}, args)
+
+	// bindings of groups are numbered after SET values
+	query, args, err = db.Table(UsersTable).WhereGroup(func(q *DB) {
+		q.Where("id", "=", 1).OrWhere("id", "=", 2)
+	}).UpdateSQL(User{ID: 5, Name: "Alex", Points: 10})
+	require.NoError(t, err)
+	require.Equal(t, `UPDATE "test_users" SET id = $1, name = $2, points = $3 WHERE (id = $4 OR id = $5)`, query)
+	require.Equal(t, []any{"5", "Alex", "10", 1, 2}, args)
+
+	// empty group is skipped
+	query, _, err = db.Table(UsersTable).Where("id", "=", 1).AndWhereGroup(func(q *DB) {}).ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT * FROM "test_users" WHERE id = $1`, query)
+}
+
+func TestDB_WhereGroupQuery(t *testing.T) {
+	_, err := db.Truncate(UsersTable)
+	require.NoError(t, err)
+
+	err = db.Table(UsersTable).InsertBatch([]User{
+		{ID: 1, Name: "Alex", Points: 10},
+		{ID: 2, Name: "Bob", Points: 20},
+		{ID: 3, Name: "Alex", Points: 30},
+	})
+	require.NoError(t, err)
+
+	cnt, err := db.Table(UsersTable).Where("points", ">", 15).AndWhereGroup(func(q *DB) {
+		q.Where("name", "=", "Alex").OrWhere("id", "=", 2)
+	}).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), cnt)
+
+	affected, err := db.Table(UsersTable).WhereGroup(func(q *DB) {
+		q.Where("id", "=", 1).OrWhere("id", "=", 3)
+	}).AndWhere("points", "<", 20).Update(struct{ Points int64 }{Points: 100})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), affected)
+
+	sum, err := db.Table(UsersTable).Sum("points")
+	require.NoError(t, err)
+	require.Equal(t, float64(150), sum)
+
+	_, err = db.Truncate(UsersTable)
+	require.NoError(t, err)
+}
+
+func TestDB_WhereColumn(t *testing.T) {
+	query, args, err := db.Table(PostsTable).Where("user_id", "=", 1).AndWhereColumn("updated_at", ">", "created_at").
+		OrWhereColumn("test_posts.title", "=", "test_posts.post").ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT * FROM "test_posts" WHERE user_id = $1 AND "updated_at" > "created_at" OR "test_posts"."title" = "test_posts"."post"`, query)
+	require.Equal(t, []any{1}, args)
+
+	mysqlDb := NewDb(NewConnectionFromDb(&sql.DB{}), WithDialect(MySQL{}))
+	query, _, err = mysqlDb.Table(PostsTable).WhereColumn("updated_at", ">", "created_at").ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM `test_posts` WHERE `updated_at` > `created_at`", query)
+}
+
+func TestDB_JoinOn(t *testing.T) {
+	q := db.Table(UsersTable).Select("name", "title").LeftJoinOn(PostsTable, func(j *DB) {
+		j.WhereColumn("test_users.id", "=", "test_posts.user_id").AndWhere("test_posts.title", "!=", "draft").
+			OrWhereGroup(func(q *DB) {
+				q.WhereColumn("test_posts.updated_at", ">", "test_posts.created_at").AndWhereIn("test_posts.user_id", []int64{1, 2})
+			})
+	}).InnerJoin("test", "test.baz", "=", "test_users.points").Where("points", ">", 10)
+
+	query, args, err := q.ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT name, title FROM "test_users" LEFT JOIN test_posts ON "test_users"."id" = "test_posts"."user_id" AND `+
+		`test_posts.title != $1 OR ("test_posts"."updated_at" > "test_posts"."created_at" AND test_posts.user_id IN ($2, $3))  `+
+		`INNER JOIN test ON test.baz=test_users.points  WHERE points > $4`, query)
+	require.Equal(t, []any{"draft", int64(1), int64(2), 10}, args)
+
+	query, args, err = q.CountSQL()
+	require.NoError(t, err)
+	require.Contains(t, query, `WHERE points > $4`)
+	require.Equal(t, []any{"draft", int64(1), int64(2), 10}, args)
+}
+
+func TestDB_JoinOnQuery(t *testing.T) {
+	_, err := db.Truncate(UsersTable)
+	require.NoError(t, err)
+	_, err = db.Truncate(PostsTable)
+	require.NoError(t, err)
+
+	err = db.Table(UsersTable).InsertBatch([]User{{ID: 1, Name: "Alex", Points: 10}, {ID: 2, Name: "Bob", Points: 20}})
+	require.NoError(t, err)
+	type post struct {
+		Title  string
+		UserID int64 `db:"user_id"`
+	}
+	err = db.Table(PostsTable).Insert(post{Title: "Hello", UserID: 1})
+	require.NoError(t, err)
+	err = db.Table(PostsTable).Insert(post{Title: "draft", UserID: 2})
+	require.NoError(t, err)
+
+	var names []string
+	user := &User{}
+	err = db.Table(UsersTable).Select("name").InnerJoinOn(PostsTable, func(j *DB) {
+		j.WhereColumn("test_users.id", "=", "test_posts.user_id").AndWhere("test_posts.title", "!=", "draft")
+	}).Where("points", ">=", 10).EachToStruct(func(rows *sql.Rows) error {
+		err := db.Next(rows, user)
+		if err == nil {
+			names = append(names, user.Name)
+		}
+
+		return err
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Alex"}, names)
+
+	_, err = db.Truncate(UsersTable)
+	require.NoError(t, err)
+	_, err = db.Truncate(PostsTable)
+	require.NoError(t, err)
+}
+
+func TestDB_Subqueries(t *testing.T) {
+	posts := db.Table(PostsTable).Select("user_id").Where("title", "=", "Hello")
+	q := db.Table(UsersTable).Select("name").SelectSub(
+		db.Table(PostsTable).Select("COUNT(*)").WhereColumn("test_posts.user_id", "=", "test_users.id").AndWhere("title", "!=", "draft"),
+		"posts_count",
+	).Where("points", ">", 10).AndWhereInSub("id", posts).
+		OrWhereSub("points", "=", db.Table(UsersTable).Select("MAX(points)").Where("id", "<", 3))
+	// changing nested query doesn't affect the outer one
+	posts.AndWhere("user_id", "=", 1)
+
+	query, args, err := q.ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT name, (SELECT COUNT(*) FROM "test_posts" WHERE "test_posts"."user_id" = "test_users"."id" AND title != $1) AS "posts_count" `+
+		`FROM "test_users" WHERE points > $2 AND id IN (SELECT user_id FROM "test_posts" WHERE title = $3) `+
+		`OR points = (SELECT MAX(points) FROM "test_users" WHERE id < $4)`, query)
+	require.Equal(t, []any{"draft", 10, "Hello", 3}, args)
+
+	query, args, err = q.CountSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT COUNT(*) FROM "test_users" WHERE points > $1 AND id IN (SELECT user_id FROM "test_posts" WHERE title = $2) `+
+		`OR points = (SELECT MAX(points) FROM "test_users" WHERE id < $3)`, query)
+	require.Equal(t, []any{10, "Hello", 3}, args)
+
+	query, args, err = db.FromSub(
+		db.Table(PostsTable).Select("user_id", "COUNT(*) AS cnt").Where("title", "!=", "draft").GroupBy("user_id"), "p",
+	).Select("user_id").Where("cnt", ">", 1).AndWhereNotExists(db.Table(UsersTable).Select("1").Where("points", "<", 0)).ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT user_id FROM (SELECT user_id, COUNT(*) AS cnt FROM "test_posts" WHERE title != $1 GROUP BY user_id) AS "p" `+
+		`WHERE cnt > $2 AND NOT EXISTS (SELECT 1 FROM "test_users" WHERE points < $3)`, query)
+	require.Equal(t, []any{"draft", 1, 0}, args)
+
+	query, args, err = db.Table(UsersTable).Select("name").Where("points", ">", 10).Union().
+		Table(PostsTable).Select("title").WhereNotInSub("user_id", posts).ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT name FROM "test_users" WHERE points > $1 UNION `+
+		`SELECT title FROM "test_posts" WHERE user_id NOT IN (SELECT user_id FROM "test_posts" WHERE title = $2 AND user_id = $3)`, query)
+	require.Equal(t, []any{10, "Hello", 1}, args)
+
+	query, args, err = db.Table(UsersTable).WhereInSub("id", posts).UpdateSQL(User{Name: "Alex"})
+	require.NoError(t, err)
+	require.Equal(t, `UPDATE "test_users" SET id = $1, name = $2, points = $3 WHERE id IN (SELECT user_id FROM "test_posts" WHERE title = $4 AND user_id = $5)`, query)
+	require.Equal(t, []any{"0", "Alex", "0", "Hello", 1}, args)
+}
+
+func TestDB_SubqueriesQuery(t *testing.T) {
+	_, err := db.Truncate(UsersTable)
+	require.NoError(t, err)
+	_, err = db.Truncate(PostsTable)
+	require.NoError(t, err)
+
+	err = db.Table(UsersTable).InsertBatch([]User{{ID: 1, Name: "Alex", Points: 10}, {ID: 2, Name: "Bob", Points: 20}})
+	require.NoError(t, err)
+	type post struct {
+		Title  string
+		UserID int64 `db:"user_id"`
+	}
+	err = db.Table(PostsTable).InsertBatch([]post{{Title: "Hello", UserID: 1}, {Title: "World", UserID: 1}, {Title: "draft", UserID: 2}})
+	require.NoError(t, err)
+
+	type userPosts struct {
+		Name       string
+		PostsCount int64 `db:"posts_count"`
+	}
+	res := &userPosts{}
+	err = db.Table(UsersTable).Select("name").SelectSub(
+		db.Table(PostsTable).Select("COUNT(*)").WhereColumn("test_posts.user_id", "=", "test_users.id").AndWhere("title", "!=", "draft"),
+		"posts_count",
+	).WhereInSub("id", db.Table(PostsTable).Select("user_id").Where("title", "=", "Hello")).First(res)
+	require.NoError(t, err)
+	require.Equal(t, userPosts{Name: "Alex", PostsCount: 2}, *res)
+
+	cnt, err := db.FromSub(db.Table(PostsTable).Select("user_id").Where("title", "!=", "draft").GroupBy("user_id"), "p").Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), cnt)
+
+	user := &User{}
+	err = db.Table(UsersTable).WhereSub("points", ">", db.Table(UsersTable).Select("MIN(points)").Where("id", "<=", 2)).First(user)
+	require.NoError(t, err)
+	require.Equal(t, "Bob", user.Name)
+
+	_, err = db.Truncate(UsersTable)
+	require.NoError(t, err)
+	_, err = db.Truncate(PostsTable)
+	require.NoError(t, err)
+}
@@ -1,18 +1,115 @@
 package buildsqlx
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	_ "github.com/lib/pq" // to prepare PostgreSQL driver
 )
 
 // Connection encloses DB struct
 type Connection struct {
-	db *sql.DB
+	// 64-bit atomically accessed fields go first to be aligned on 32-bit platforms
+	next     uint64
+	db       *sql.DB
+	replicas []*sql.DB
+	policy   ReplicaPolicy
+	// reads of request tracked by ReadYourWrites are sent to primary during sticky window after its last write
+	sticky time.Duration
+	mu     sync.RWMutex
+	hooks  []Hook
 }
 
-// NewConnection returns pre-defined Connection structure
+// ReplicaPolicy defines how replica is chosen for read queries
+type ReplicaPolicy int
+
+const (
+	// ReplicaRoundRobin chooses replicas one by one
+	ReplicaRoundRobin ReplicaPolicy = iota
+	// ReplicaRandom chooses replica randomly
+	ReplicaRandom
+)
+
+// ConnOption configures Connection and its pool on Open
+type ConnOption func(*connConfig)
+
+// connConfig collects pool settings applied on Open, nil values keep database/sql defaults
+type connConfig struct {
+	maxOpenConns    *int
+	maxIdleConns    *int
+	connMaxLifetime *time.Duration
+	connMaxIdleTime *time.Duration
+	pingTimeout     *time.Duration
+	replicaDSNs     []string
+	policy          ReplicaPolicy
+	sticky          time.Duration
+}
+
+// WithMaxOpenConns sets the maximum number of open connections, <= 0 means unlimited
+func WithMaxOpenConns(n int) ConnOption {
+	return func(c *connConfig) {
+		c.maxOpenConns = &n
+	}
+}
+
+// WithMaxIdleConns sets the maximum number of idle connections kept in the pool, <= 0 means no idle connections are retained
+func WithMaxIdleConns(n int) ConnOption {
+	return func(c *connConfig) {
+		c.maxIdleConns = &n
+	}
+}
+
+// WithConnMaxLifetime sets the maximum amount of time a connection may be reused
+func WithConnMaxLifetime(d time.Duration) ConnOption {
+	return func(c *connConfig) {
+		c.connMaxLifetime = &d
+	}
+}
+
+// WithConnMaxIdleTime sets the maximum amount of time a connection may be idle before it's closed
+func WithConnMaxIdleTime(d time.Duration) ConnOption {
+	return func(c *connConfig) {
+		c.connMaxIdleTime = &d
+	}
+}
+
+// WithPing verifies the connection to database is alive on Open, failing if there is no response within timeout
+func WithPing(timeout time.Duration) ConnOption {
+	return func(c *connConfig) {
+		c.pingTimeout = &timeout
+	}
+}
+
+// WithReplicas opens read replicas with the same driver and pool options as primary,
+// read queries are sent to replicas while writes and transactions are run on primary
+func WithReplicas(dataSourceNames ...string) ConnOption {
+	return func(c *connConfig) {
+		c.replicaDSNs = append(c.replicaDSNs, dataSourceNames...)
+	}
+}
+
+// WithReplicaPolicy sets the policy replicas are chosen by, ReplicaRoundRobin is used by default
+func WithReplicaPolicy(p ReplicaPolicy) ConnOption {
+	return func(c *connConfig) {
+		c.policy = p
+	}
+}
+
+// WithStickyWrites sends read queries of request to primary during window after its last write,
+// so recently written data can be read despite of replication lag, requests are tracked by ReadYourWrites context
+func WithStickyWrites(window time.Duration) ConnOption {
+	return func(c *connConfig) {
+		c.sticky = window
+	}
+}
+
+// NewConnection returns pre-defined Connection structure,
+// it exits the process if the driver can't be opened - use Open to handle an error instead
 func NewConnection(driverName, dataSourceName string) *Connection {
 	db, err := sql.Open(driverName, dataSourceName)
 	if err != nil {
@@ -22,7 +119,174 @@ func NewConnection(driverName, dataSourceName string) *Connection {
 	return &Connection{db: db}
 }
 
+// Open returns Connection with pool configured by options,
+// an error is returned if the driver can't be opened or database isn't reachable with WithPing option set
+func Open(driverName, dataSourceName string, opts ...ConnOption) (*Connection, error) {
+	cfg := &connConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	db, err := cfg.open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &Connection{db: db, policy: cfg.policy, sticky: cfg.sticky}
+	for _, dsn := range cfg.replicaDSNs {
+		replica, err := cfg.open(driverName, dsn)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+
+		conn.replicas = append(conn.replicas, replica)
+	}
+
+	return conn, nil
+}
+
+// NewReplicatedConnection returns Connection with primary and replicas db handles,
+// pool options are applied to all handles, while WithPing and WithReplicas are ignored
+func NewReplicatedConnection(primary *sql.DB, replicas []*sql.DB, opts ...ConnOption) *Connection {
+	cfg := &connConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	cfg.configure(primary)
+	for _, replica := range replicas {
+		cfg.configure(replica)
+	}
+
+	return &Connection{db: primary, replicas: replicas, policy: cfg.policy, sticky: cfg.sticky}
+}
+
+// open opens db handle with pool configured and pings it if needed
+func (cfg *connConfig) open(driverName, dataSourceName string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.configure(db)
+	if cfg.pingTimeout != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), *cfg.pingTimeout)
+		defer cancel()
+
+		if err = db.PingContext(ctx); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+
+	return db, nil
+}
+
+// configure sets pool options to db handle
+func (cfg *connConfig) configure(db *sql.DB) {
+	if cfg.maxOpenConns != nil {
+		db.SetMaxOpenConns(*cfg.maxOpenConns)
+	}
+
+	if cfg.maxIdleConns != nil {
+		db.SetMaxIdleConns(*cfg.maxIdleConns)
+	}
+
+	if cfg.connMaxLifetime != nil {
+		db.SetConnMaxLifetime(*cfg.connMaxLifetime)
+	}
+
+	if cfg.connMaxIdleTime != nil {
+		db.SetConnMaxIdleTime(*cfg.connMaxIdleTime)
+	}
+}
+
 // NewConnectionFromDb returns re-defined Connection structure created via db handle with connection(s)
 func NewConnectionFromDb(db *sql.DB) *Connection {
 	return &Connection{db: db}
 }
+
+// Stats returns connection pool statistics e.g.: to observe pool saturation
+func (c *Connection) Stats() sql.DBStats {
+	return c.db.Stats()
+}
+
+// ReplicaStats returns connection pool statistics per replica
+func (c *Connection) ReplicaStats() []sql.DBStats {
+	stats := make([]sql.DBStats, len(c.replicas))
+	for i, replica := range c.replicas {
+		stats[i] = replica.Stats()
+	}
+
+	return stats
+}
+
+// Ping verifies connections to primary and replicas are alive
+func (c *Connection) Ping(ctx context.Context) error {
+	if err := c.db.PingContext(ctx); err != nil {
+		return err
+	}
+
+	for _, replica := range c.replicas {
+		if err := replica.PingContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeSession is the last write time of request reads are sticky to
+type writeSession struct {
+	lastWrite int64
+}
+
+type writeSessionKey struct{}
+
+// ReadYourWrites returns ctx tracking writes made with it e.g. per http request, so reads with the returned ctx
+// are sent to primary during WithStickyWrites window after the last of them, while reads with other contexts still go to replicas
+func ReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, writeSessionKey{}, &writeSession{})
+}
+
+// replica chooses db handle for read query by policy, primary is returned if there are no replicas
+// or sticky window after the last write of request tracked by ctx hasn't passed yet
+func (c *Connection) replica(ctx context.Context) *sql.DB {
+	if len(c.replicas) == 0 {
+		return c.db
+	}
+
+	if s, ok := ctx.Value(writeSessionKey{}).(*writeSession); ok && c.sticky > 0 &&
+		time.Since(time.Unix(0, atomic.LoadInt64(&s.lastWrite))) < c.sticky {
+		return c.db
+	}
+
+	if c.policy == ReplicaRandom {
+		return c.replicas[rand.Intn(len(c.replicas))]
+	}
+
+	return c.replicas[(atomic.AddUint64(&c.next, 1)-1)%uint64(len(c.replicas))]
+}
+
+// wrote tracks the last write time of request tracked by ctx to apply sticky window to its reads
+func (c *Connection) wrote(ctx context.Context) {
+	if s, ok := ctx.Value(writeSessionKey{}).(*writeSession); ok && c.sticky > 0 {
+		atomic.StoreInt64(&s.lastWrite, time.Now().UnixNano())
+	}
+}
+
+// Close closes primary and replicas db handles
+func (c *Connection) Close() (err error) {
+	if c.db != nil {
+		err = c.db.Close()
+	}
+
+	for _, replica := range c.replicas {
+		if errReplica := replica.Close(); errReplica != nil && err == nil {
+			err = errReplica
+		}
+	}
+
+	return err
+}
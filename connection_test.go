@@ -0,0 +1,97 @@
+package buildsqlx
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	conn, err := Open("postgres", dbConnInfo, WithMaxOpenConns(5), WithMaxIdleConns(2),
+		WithConnMaxLifetime(time.Minute), WithConnMaxIdleTime(30*time.Second), WithPing(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, 5, conn.Stats().MaxOpenConnections)
+
+	_, err = NewDb(conn).Table(TestTable).Count()
+	require.NoError(t, err)
+	require.LessOrEqual(t, conn.Stats().OpenConnections, 5)
+}
+
+func TestOpen_Errs(t *testing.T) {
+	_, err := Open("unknown", dbConnInfo)
+	require.EqualError(t, err, `sql: unknown driver "unknown" (forgotten import?)`)
+
+	_, err = Open("postgres", "host=localhost port=1 user=postgres sslmode=disable", WithPing(time.Second))
+	require.Error(t, err)
+}
+
+func TestConnection_Replicas(t *testing.T) {
+	primary, replica1, replica2 := &sql.DB{}, &sql.DB{}, &sql.DB{}
+	rdb := NewDb(NewReplicatedConnection(primary, []*sql.DB{replica1, replica2}))
+	require.Same(t, replica1, rdb.reader(context.Background()))
+	require.Same(t, replica2, rdb.reader(context.Background()))
+	require.Same(t, replica1, rdb.reader(context.Background()))
+	require.Same(t, primary, rdb.Sql())
+
+	// explicit override doesn't affect the original DB
+	require.Same(t, primary, rdb.OnPrimary().Table(TestTable).reader(context.Background()))
+	require.Same(t, replica2, rdb.reader(context.Background()))
+
+	// reads and writes in transaction go to the transaction
+	tx := &sql.Tx{}
+	rdb.Txn = &Txn{Tx: tx}
+	require.Same(t, tx, rdb.reader(context.Background()))
+	require.Same(t, tx, rdb.writer())
+	rdb.Txn = nil
+
+	rdb = NewDb(NewReplicatedConnection(primary, []*sql.DB{replica1, replica2}, WithReplicaPolicy(ReplicaRandom)))
+	for i := 0; i < 10; i++ {
+		require.Contains(t, []*sql.DB{replica1, replica2}, rdb.reader(context.Background()))
+	}
+}
+
+func TestConnection_StickyWrites(t *testing.T) {
+	primary, replica := &sql.DB{}, &sql.DB{}
+	conn := NewReplicatedConnection(primary, []*sql.DB{replica}, WithStickyWrites(50*time.Millisecond))
+	rdb := NewDb(conn)
+	ctx, otherCtx := ReadYourWrites(context.Background()), ReadYourWrites(context.Background())
+	require.Same(t, replica, rdb.reader(ctx))
+
+	conn.wrote(ctx)
+	require.Same(t, primary, rdb.reader(ctx))
+	// reads of unrelated requests still go to replica
+	require.Same(t, replica, rdb.reader(otherCtx))
+	require.Same(t, replica, rdb.reader(context.Background()))
+
+	// writes without tracked request don't pin anyone to primary
+	conn.wrote(context.Background())
+	require.Same(t, replica, rdb.reader(otherCtx))
+
+	time.Sleep(60 * time.Millisecond)
+	require.Same(t, replica, rdb.reader(ctx))
+}
+
+func TestOpen_WithReplicas(t *testing.T) {
+	conn, err := Open("postgres", dbConnInfo, WithReplicas(dbConnInfo), WithStickyWrites(time.Second), WithPing(time.Second))
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Len(t, conn.ReplicaStats(), 1)
+
+	rdb := NewDb(conn)
+	_, err = rdb.Truncate(TestTable)
+	require.NoError(t, err)
+
+	ctx := ReadYourWrites(context.Background())
+	err = rdb.Table(TestTable).InsertCtx(ctx, data)
+	require.NoError(t, err)
+
+	cnt, err := rdb.Table(TestTable).CountCtx(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), cnt)
+
+	_, err = rdb.Truncate(TestTable)
+	require.NoError(t, err)
+}
@@ -0,0 +1,142 @@
+package buildsqlx
+
+import "strings"
+
+const (
+	cteMaterialized    = "MATERIALIZED"
+	cteNotMaterialized = "NOT MATERIALIZED"
+)
+
+// cte is a common table expression of WITH clause, which is either select query or data-modifying stmt
+type cte struct {
+	name         string
+	columns      []string
+	recursive    bool
+	materialized string
+	b            *builder
+	op           Op
+	data         any
+	returning    []string
+}
+
+// With adds common table expression name of q to WITH clause, so the query can select from it by Table(name), ex.:
+// Table("top").With("top", db.Table("users").Where("points", ">", 100)) for WITH "top" AS (SELECT * FROM "users" WHERE points > $1) SELECT * FROM "top"
+func (r *DB) With(name string, q *DB) *DB {
+	return r.buildWith(cte{name: name, op: OpSelect, b: q.Builder.clone()})
+}
+
+// WithRecursive adds recursive common table expression name with columns of q to WITH RECURSIVE clause,
+// q is the non-recursive part glued by Union/UnionAll with the recursive one referring to name
+func (r *DB) WithRecursive(name string, columns []string, q *DB) *DB {
+	return r.buildWith(cte{name: name, columns: columns, recursive: true, op: OpSelect, b: q.Builder.clone()})
+}
+
+// WithMaterialized adds common table expression name of q hinted to be computed once as MATERIALIZED
+func (r *DB) WithMaterialized(name string, q *DB) *DB {
+	return r.buildWith(cte{name: name, materialized: cteMaterialized, op: OpSelect, b: q.Builder.clone()})
+}
+
+// WithNotMaterialized adds common table expression name of q hinted to be folded into the query as NOT MATERIALIZED
+func (r *DB) WithNotMaterialized(name string, q *DB) *DB {
+	return r.buildWith(cte{name: name, materialized: cteNotMaterialized, op: OpSelect, b: q.Builder.clone()})
+}
+
+// WithInsert adds data-modifying common table expression name inserting data struct into the table of q,
+// returning columns are selected from the expression, e.g. "*" for all of them
+func (r *DB) WithInsert(name string, q *DB, data any, returning ...string) *DB {
+	return r.buildWith(cte{name: name, op: OpInsert, b: q.Builder.clone(), data: data, returning: returning})
+}
+
+// WithUpdate adds data-modifying common table expression name updating rows of q with data struct,
+// returning columns are selected from the expression, e.g. "*" for all of them
+func (r *DB) WithUpdate(name string, q *DB, data any, returning ...string) *DB {
+	return r.buildWith(cte{name: name, op: OpUpdate, b: q.Builder.clone(), data: data, returning: returning})
+}
+
+// WithDelete adds data-modifying common table expression name deleting rows of q, ex.:
+// Table("archive").WithDelete("moved", db.Table("posts").Where("id", "<", 100), "*").InsertUsing(nil, db.Table("moved"))
+// for WITH "moved" AS (DELETE FROM "posts" WHERE id < $1 RETURNING *) INSERT INTO "archive" SELECT * FROM "moved"
+func (r *DB) WithDelete(name string, q *DB, returning ...string) *DB {
+	return r.buildWith(cte{name: name, op: OpDelete, b: q.Builder.clone(), returning: returning})
+}
+
+func (r *DB) buildWith(c cte) *DB {
+	r.Builder.ctes = append(r.Builder.ctes, c)
+	return r
+}
+
+// compileWith constructs WITH clause numbering placeholders from i, it's empty if there are no expressions
+func (r *builder) compileWith(i *int) string {
+	if len(r.ctes) == 0 {
+		return ""
+	}
+
+	clause := "WITH "
+	expressions := make([]string, 0, len(r.ctes))
+	for _, c := range r.ctes {
+		if c.recursive {
+			clause = "WITH RECURSIVE "
+		}
+
+		expressions = append(expressions, c.compile(r.dialect, i))
+	}
+
+	return clause + strings.Join(expressions, ", ") + " "
+}
+
+// withArgs returns values bound to WITH clause in order of their placeholders
+func (r *builder) withArgs() []any {
+	var args []any
+	for _, c := range r.ctes {
+		args = append(args, c.args()...)
+	}
+
+	return args
+}
+
+// compile constructs expression numbering placeholders from i
+func (c cte) compile(d Dialect, i *int) string {
+	expr := d.Quote(c.name)
+	if len(c.columns) > 0 {
+		expr += "(" + strings.Join(c.columns, ", ") + ")"
+	}
+
+	expr += " AS "
+	if c.materialized != "" {
+		expr += c.materialized + " "
+	}
+
+	var stmt string
+	switch c.op {
+	case OpInsert:
+		stmt = c.b.compileInsert(c.data, i)
+	case OpUpdate:
+		stmt = c.b.compileUpdate(c.data, i)
+	case OpDelete:
+		stmt = c.b.compileDelete(i)
+	default:
+		return expr + "(" + c.b.compileQuery(i) + ")"
+	}
+
+	if len(c.returning) > 0 {
+		stmt += " RETURNING " + strings.Join(c.returning, ", ")
+	}
+
+	return expr + "(" + stmt + ")"
+}
+
+// args returns values bound to expression in order of their placeholders
+func (c cte) args() []any {
+	switch c.op {
+	case OpInsert:
+		_, values, _ := prepareBindingsForStruct(c.b.dialect, c.data)
+		return append(c.b.withArgs(), values...)
+	case OpUpdate:
+		_, values, _ := prepareBindingsForStruct(c.b.dialect, c.data)
+		return append(append(c.b.withArgs(), values...), c.b.clauseArgs()...)
+	case OpDelete:
+		return append(c.b.withArgs(), c.b.clauseArgs()...)
+	}
+
+	return c.b.queryArgs()
+}
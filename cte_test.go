@@ -0,0 +1,132 @@
+package buildsqlx
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type ctePost struct {
+	Title  string
+	UserID int64 `db:"user_id"`
+}
+
+func TestDB_With(t *testing.T) {
+	q := db.Table("top").With("top", db.Table(UsersTable).Where("points", ">", 10)).Select("name").Where("id", "<", 5)
+
+	query, args, err := q.ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `WITH "top" AS (SELECT * FROM "test_users" WHERE points > $1) SELECT name FROM "top" WHERE id < $2`, query)
+	require.Equal(t, []any{10, 5}, args)
+
+	query, args, err = q.CountSQL()
+	require.NoError(t, err)
+	require.Equal(t, `WITH "top" AS (SELECT * FROM "test_users" WHERE points > $1) SELECT COUNT(*) FROM "top" WHERE id < $2`, query)
+	require.Equal(t, []any{10, 5}, args)
+
+	query, _, err = q.ExistsSQL()
+	require.NoError(t, err)
+	require.Equal(t, `WITH "top" AS (SELECT * FROM "test_users" WHERE points > $1) SELECT EXISTS(SELECT 1 FROM "top" WHERE id < $2)`, query)
+
+	// expressions don't leak into queries started from the base one
+	query, args, err = q.Table(PostsTable).Where("id", "=", 1).ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT * FROM "test_posts" WHERE id = $1`, query)
+	require.Equal(t, []any{1}, args)
+
+	// expressions are carried over by Table and rendered once for the whole union
+	query, args, err = db.Table(UsersTable).WithMaterialized("top", db.Table(UsersTable).Where("points", ">", 10)).
+		WithNotMaterialized("posts", db.Table(PostsTable).Where("title", "!=", "draft")).Select("name").Union().
+		Table("top").Select("name").WhereInSub("id", db.Table("posts").Select("user_id")).ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `WITH "top" AS MATERIALIZED (SELECT * FROM "test_users" WHERE points > $1), `+
+		`"posts" AS NOT MATERIALIZED (SELECT * FROM "test_posts" WHERE title != $2) `+
+		`SELECT name FROM "test_users" UNION SELECT name FROM "top" WHERE id IN (SELECT user_id FROM "posts")`, query)
+	require.Equal(t, []any{10, "draft"}, args)
+
+	query, args, err = db.Table("tree").WithRecursive("tree", []string{"id", "depth"},
+		db.Table(UsersTable).Select("id", "0").Where("id", "=", 1).UnionAll().
+			Table(UsersTable).Select("test_users.id", "tree.depth + 1").InnerJoin("tree", "tree.id + 1", "=", "test_users.id").
+			Where("tree.depth", "<", 3),
+	).Select("id").Where("depth", ">", 0).ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `WITH RECURSIVE "tree"(id, depth) AS (SELECT id, 0 FROM "test_users" WHERE id = $1 UNION ALL `+
+		`SELECT test_users.id, tree.depth + 1 FROM "test_users" INNER JOIN tree ON tree.id + 1=test_users.id  WHERE tree.depth < $2) `+
+		`SELECT id FROM "tree" WHERE depth > $3`, query)
+	require.Equal(t, []any{1, 3, 0}, args)
+
+	mysqlDb := NewDb(NewConnectionFromDb(&sql.DB{}), WithDialect(MySQL{}))
+	query, args, err = mysqlDb.Table("top").With("top", mysqlDb.Table(UsersTable).Where("points", ">", 10)).Where("id", "<", 5).ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, "WITH `top` AS (SELECT * FROM `test_users` WHERE points > ?) SELECT * FROM `top` WHERE id < ?", query)
+	require.Equal(t, []any{10, 5}, args)
+}
+
+func TestDB_WithDataModifying(t *testing.T) {
+	query, args, err := db.Table("archive").WithDelete("moved", db.Table(PostsTable).Where("user_id", "=", 1), "*").
+		InsertUsingSQL([]string{"title", "user_id"}, db.Table("moved").Select("title", "user_id").Where("title", "!=", "draft"))
+	require.NoError(t, err)
+	require.Equal(t, `WITH "moved" AS (DELETE FROM "test_posts" WHERE user_id = $1 RETURNING *) `+
+		`INSERT INTO "archive" (title, user_id) SELECT title, user_id FROM "moved" WHERE title != $2`, query)
+	require.Equal(t, []any{1, "draft"}, args)
+
+	query, args, err = db.Table(UsersTable).WithUpdate("bumped", db.Table(PostsTable).Where("title", "=", "draft"),
+		ctePost{Title: "Hello", UserID: 2}, "user_id").WhereInSub("id", db.Table("bumped").Select("user_id")).DeleteSQL()
+	require.NoError(t, err)
+	require.Equal(t, `WITH "bumped" AS (UPDATE "test_posts" SET title = $1, user_id = $2 WHERE title = $3 RETURNING user_id) `+
+		`DELETE FROM "test_users" WHERE id IN (SELECT user_id FROM "bumped")`, query)
+	require.Equal(t, []any{"Hello", "2", "draft"}, args)
+
+	query, args, err = db.Table(UsersTable).With("top", db.Table(UsersTable).Select("id").Where("points", ">", 100)).
+		WhereInSub("id", db.Table("top").Select("id")).AndWhere("points", "<", 1000).UpdateSQL(User{Name: "Alex"})
+	require.NoError(t, err)
+	require.Equal(t, `WITH "top" AS (SELECT id FROM "test_users" WHERE points > $1) `+
+		`UPDATE "test_users" SET id = $2, name = $3, points = $4 WHERE id IN (SELECT id FROM "top") AND points < $5`, query)
+	require.Equal(t, []any{100, "0", "Alex", "0", 1000}, args)
+
+	query, args, err = db.Table("added").WithInsert("added", db.Table(PostsTable), ctePost{Title: "Hello", UserID: 1}, "user_id").ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `WITH "added" AS (INSERT INTO "test_posts" (title, user_id) VALUES($1, $2) RETURNING user_id) SELECT * FROM "added"`, query)
+	require.Equal(t, []any{"Hello", "1"}, args)
+}
+
+func TestDB_WithQuery(t *testing.T) {
+	_, err := db.Truncate(UsersTable)
+	require.NoError(t, err)
+	_, err = db.Truncate(PostsTable)
+	require.NoError(t, err)
+
+	err = db.Table(UsersTable).InsertBatch(batchUsers)
+	require.NoError(t, err)
+	err = db.Table(PostsTable).InsertBatch([]ctePost{{Title: "Hello", UserID: 1}, {Title: "draft", UserID: 2}, {Title: "draft", UserID: 3}})
+	require.NoError(t, err)
+
+	cnt, err := db.Table("top").With("top", db.Table(UsersTable).Where("points", ">", 1000)).Where("name", "=", TestUserName).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), cnt)
+
+	sum, err := db.Table("seq").WithRecursive("seq", []string{"n"},
+		db.Table(UsersTable).Select("MIN(id)").UnionAll().Table("seq").Select("n + 1").Where("n", "<", 10),
+	).Sum("n")
+	require.NoError(t, err)
+	require.Equal(t, float64(55), sum)
+
+	// drafts are moved from posts to users
+	moved, err := db.Table(UsersTable).WithDelete("moved", db.Table(PostsTable).Where("title", "=", "draft"), "*").
+		InsertUsing([]string{"id", "name", "points"}, db.Table("moved").Select("user_id + 10", "title", "0"))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), moved)
+
+	cnt, err = db.Table(PostsTable).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), cnt)
+	cnt, err = db.Table(UsersTable).Where("name", "=", "draft").Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), cnt)
+
+	_, err = db.Truncate(UsersTable)
+	require.NoError(t, err)
+	_, err = db.Truncate(PostsTable)
+	require.NoError(t, err)
+}
@@ -0,0 +1,406 @@
+package buildsqlx
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Dialect encapsulates SQL syntax specifics of a particular database system
+type Dialect interface {
+	// Name returns the database system name e.g.: postgres, mysql
+	Name() string
+	// Placeholder returns the bind parameter for n-th argument, n starts from 1
+	Placeholder(n int) string
+	// Quote wraps an identifier e.g. table name into dialect specific quotes
+	Quote(ident string) string
+	// Random returns an expression to order rows randomly
+	Random() string
+	// LimitOffset builds LIMIT/OFFSET clause, zero values are omitted
+	LimitOffset(limit, offset int64) string
+	// LockForUpdate returns row locking clause, empty string if locks are not supported
+	LockForUpdate() string
+	// Upsert builds the clause appended to INSERT stmt to update columns if conflicting row has been found
+	Upsert(conflict string, columns []string) string
+	// Returning reports whether INSERT ... RETURNING id is supported, otherwise LastInsertId is used
+	Returning() bool
+	// CopyIn returns COPY stmt to stream batch inserts, empty string means multi-row INSERT is used instead
+	CopyIn(table string, columns []string) string
+	// MaxPlaceholders returns the max number of bind parameters per stmt
+	MaxPlaceholders() int
+	// HasTableQuery returns a query with args to check whether table exists in particular schema
+	HasTableQuery(schema, tbl string) (string, []any)
+	// HasColumnQuery returns a query with args to check whether column exists in particular schema/table
+	HasColumnQuery(schema, tbl, col string) (string, []any)
+	// SetLocal returns a query with args to change setting for the current transaction only,
+	// empty query means transaction-local settings are not supported
+	SetLocal(name, value string) (string, []any)
+	// Deferrable returns stmt making SERIALIZABLE READ ONLY transaction deferrable, empty string if not supported
+	Deferrable() string
+	// SchemaGrammar describes DDL features available for Schema
+	SchemaGrammar() SchemaGrammar
+}
+
+// SchemaGrammar describes DDL features the dialect can express, options that are only hints e.g.: comments,
+// CONCURRENTLY or INCLUDE are skipped if not supported, while unsupported alterations are returned as errors by Schema
+type SchemaGrammar struct {
+	// DefaultSchema is the schema Schema checks tables/columns existence in
+	DefaultSchema string
+	// Serial and BigSerial are the types for auto incremented primary keys
+	Serial    string
+	BigSerial string
+	// CurrentDateTime is the default value for DateTime/DateTimeTz columns
+	CurrentDateTime string
+	// Truncate reports whether TRUNCATE is supported, otherwise DELETE FROM is used
+	Truncate bool
+	// Comments reports whether COMMENT ON TABLE/COLUMN is supported
+	Comments bool
+	// IndexOptions reports whether CONCURRENTLY and INCLUDE(...) index options are supported
+	IndexOptions bool
+	// RenameIndex reports whether ALTER INDEX ... RENAME TO is supported
+	RenameIndex bool
+	// AlterColumnType reports whether ALTER COLUMN ... TYPE is supported
+	AlterColumnType bool
+	// AddConstraint reports whether foreign keys are added via ALTER TABLE ... ADD CONSTRAINT,
+	// otherwise they are declared in column definition with REFERENCES
+	AddConstraint bool
+	// ColumnIfExists reports whether IF [NOT] EXISTS is supported for ADD/DROP COLUMN
+	ColumnIfExists bool
+	// BackslashEscapes reports whether backslash is escape char in string literals, so it's doubled in DEFAULT values
+	BackslashEscapes bool
+}
+
+// Option configures DB on construction
+type Option func(*DB)
+
+// WithDialect sets the SQL dialect DB builds queries for, PostgreSQL is used by default
+func WithDialect(d Dialect) Option {
+	return func(r *DB) {
+		r.Builder.dialect = d
+	}
+}
+
+// quoteIdent quotes every part of qualified identifier e.g. users.id, so the parts are quoted separately,
+// while * is left as is e.g. users.*
+func quoteIdent(d Dialect, ident string) string {
+	parts := strings.Split(ident, ".")
+	for i, part := range parts {
+		if part != "*" {
+			parts[i] = d.Quote(part)
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// Postgres is the PostgreSQL dialect
+type Postgres struct{}
+
+// Name returns postgres
+func (Postgres) Name() string {
+	return "postgres"
+}
+
+// Placeholder returns $n bind parameter
+func (Postgres) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// Quote wraps an identifier into double quotes
+func (Postgres) Quote(ident string) string {
+	return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+}
+
+// Random returns random() function
+func (Postgres) Random() string {
+	return "random()"
+}
+
+// LimitOffset builds LIMIT/OFFSET clause
+func (Postgres) LimitOffset(limit, offset int64) (clause string) {
+	if limit > 0 {
+		clause += " LIMIT " + strconv.FormatInt(limit, 10)
+	}
+
+	if offset > 0 {
+		clause += " OFFSET " + strconv.FormatInt(offset, 10)
+	}
+
+	return
+}
+
+// LockForUpdate returns FOR UPDATE clause
+func (Postgres) LockForUpdate() string {
+	return " FOR UPDATE"
+}
+
+// Upsert builds ON CONFLICT(conflict) DO UPDATE SET clause
+func (Postgres) Upsert(conflict string, columns []string) string {
+	sets := make([]string, len(columns))
+	for i, col := range columns {
+		sets[i] = col + " = excluded." + col
+	}
+
+	return " ON CONFLICT(" + conflict + ") DO UPDATE SET " + strings.Join(sets, ", ")
+}
+
+// Returning reports that PostgreSQL supports RETURNING
+func (Postgres) Returning() bool {
+	return true
+}
+
+// CopyIn returns COPY FROM STDIN stmt supported by lib/pq driver
+func (Postgres) CopyIn(table string, columns []string) string {
+	return pq.CopyIn(table, columns...)
+}
+
+// MaxPlaceholders returns the max number of bind parameters PostgreSQL accepts
+func (Postgres) MaxPlaceholders() int {
+	return 65535
+}
+
+// HasTableQuery checks table existence via pg_tables
+func (Postgres) HasTableQuery(schema, tbl string) (string, []any) {
+	return "SELECT EXISTS (SELECT 1 FROM pg_tables WHERE schemaname = $1 AND tablename = $2)", []any{schema, tbl}
+}
+
+// HasColumnQuery checks column existence via information_schema
+func (Postgres) HasColumnQuery(schema, tbl, col string) (string, []any) {
+	return "SELECT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = $1 AND table_name = $2 AND column_name = $3)",
+		[]any{schema, tbl, col}
+}
+
+// SetLocal changes setting via set_config() with is_local flag, so the value is bound instead of being inlined
+func (Postgres) SetLocal(name, value string) (string, []any) {
+	return "SELECT set_config($1, $2, true)", []any{name, value}
+}
+
+// Deferrable sets DEFERRABLE mode before the 1st query of transaction
+func (Postgres) Deferrable() string {
+	return "SET TRANSACTION DEFERRABLE"
+}
+
+// SchemaGrammar returns the full set of DDL features Schema is designed for
+func (Postgres) SchemaGrammar() SchemaGrammar {
+	return SchemaGrammar{
+		DefaultSchema:   DefaultSchema,
+		Serial:          TypeSerial,
+		BigSerial:       TypeBigSerial,
+		CurrentDateTime: CurrentDateTime,
+		Truncate:        true,
+		Comments:        true,
+		IndexOptions:    true,
+		RenameIndex:     true,
+		AlterColumnType: true,
+		AddConstraint:   true,
+		ColumnIfExists:  true,
+	}
+}
+
+// MySQL is the MySQL/MariaDB dialect
+type MySQL struct{}
+
+// Name returns mysql
+func (MySQL) Name() string {
+	return "mysql"
+}
+
+// Placeholder returns ? bind parameter
+func (MySQL) Placeholder(int) string {
+	return "?"
+}
+
+// Quote wraps an identifier into backticks
+func (MySQL) Quote(ident string) string {
+	return "`" + strings.ReplaceAll(ident, "`", "``") + "`"
+}
+
+// Random returns RAND() function
+func (MySQL) Random() string {
+	return "RAND()"
+}
+
+// LimitOffset builds LIMIT/OFFSET clause, as MySQL doesn't accept OFFSET without LIMIT - the max one is set
+func (MySQL) LimitOffset(limit, offset int64) (clause string) {
+	if limit > 0 {
+		clause += " LIMIT " + strconv.FormatInt(limit, 10)
+	} else if offset > 0 {
+		clause += " LIMIT 18446744073709551615"
+	}
+
+	if offset > 0 {
+		clause += " OFFSET " + strconv.FormatInt(offset, 10)
+	}
+
+	return
+}
+
+// LockForUpdate returns FOR UPDATE clause
+func (MySQL) LockForUpdate() string {
+	return " FOR UPDATE"
+}
+
+// Upsert builds ON DUPLICATE KEY UPDATE clause, conflict is resolved by MySQL via PRIMARY/UNIQUE keys
+func (MySQL) Upsert(_ string, columns []string) string {
+	sets := make([]string, len(columns))
+	for i, col := range columns {
+		sets[i] = col + " = VALUES(" + col + ")"
+	}
+
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+}
+
+// Returning reports that MySQL doesn't support RETURNING, LAST_INSERT_ID() is used instead
+func (MySQL) Returning() bool {
+	return false
+}
+
+// CopyIn returns an empty string as MySQL uses multi-row INSERT for batches
+func (MySQL) CopyIn(string, []string) string {
+	return ""
+}
+
+// MaxPlaceholders returns the max number of bind parameters MySQL accepts in prepared stmt
+func (MySQL) MaxPlaceholders() int {
+	return 65535
+}
+
+// HasTableQuery checks table existence via information_schema, schema is the database name - current one if empty
+func (MySQL) HasTableQuery(schema, tbl string) (string, []any) {
+	return "SELECT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?)",
+		[]any{schema, tbl}
+}
+
+// HasColumnQuery checks column existence via information_schema, schema is the database name - current one if empty
+func (MySQL) HasColumnQuery(schema, tbl, col string) (string, []any) {
+	return "SELECT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ? AND column_name = ?)",
+		[]any{schema, tbl, col}
+}
+
+// SetLocal returns an empty query as MySQL has session settings only
+func (MySQL) SetLocal(string, string) (string, []any) {
+	return "", nil
+}
+
+// Deferrable returns an empty string as MySQL has no deferrable transactions
+func (MySQL) Deferrable() string {
+	return ""
+}
+
+// SchemaGrammar returns DDL features of MySQL, tables are looked up in the current database
+func (MySQL) SchemaGrammar() SchemaGrammar {
+	return SchemaGrammar{
+		Serial:           TypeSerial,
+		BigSerial:        TypeSerial,
+		CurrentDateTime:  "CURRENT_TIMESTAMP",
+		Truncate:         true,
+		AddConstraint:    true,
+		BackslashEscapes: true,
+	}
+}
+
+// SQLite is the SQLite dialect
+type SQLite struct{}
+
+// Name returns sqlite
+func (SQLite) Name() string {
+	return "sqlite"
+}
+
+// Placeholder returns ? bind parameter
+func (SQLite) Placeholder(int) string {
+	return "?"
+}
+
+// Quote wraps an identifier into double quotes
+func (SQLite) Quote(ident string) string {
+	return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+}
+
+// Random returns random() function
+func (SQLite) Random() string {
+	return "random()"
+}
+
+// LimitOffset builds LIMIT/OFFSET clause, as SQLite doesn't accept OFFSET without LIMIT - the negative (unbounded) one is set
+func (SQLite) LimitOffset(limit, offset int64) (clause string) {
+	if limit > 0 {
+		clause += " LIMIT " + strconv.FormatInt(limit, 10)
+	} else if offset > 0 {
+		clause += " LIMIT -1"
+	}
+
+	if offset > 0 {
+		clause += " OFFSET " + strconv.FormatInt(offset, 10)
+	}
+
+	return
+}
+
+// LockForUpdate returns an empty string as SQLite locks the whole database on write
+func (SQLite) LockForUpdate() string {
+	return ""
+}
+
+// Upsert builds ON CONFLICT(conflict) DO UPDATE SET clause
+func (SQLite) Upsert(conflict string, columns []string) string {
+	return Postgres{}.Upsert(conflict, columns)
+}
+
+// Returning reports that last_insert_rowid() is used to get id
+func (SQLite) Returning() bool {
+	return false
+}
+
+// CopyIn returns an empty string as SQLite uses multi-row INSERT for batches
+func (SQLite) CopyIn(string, []string) string {
+	return ""
+}
+
+// MaxPlaceholders returns SQLITE_MAX_VARIABLE_NUMBER default for SQLite versions prior to 3.32.0
+func (SQLite) MaxPlaceholders() int {
+	return 999
+}
+
+// HasTableQuery checks table existence via sqlite_master, schema is the database name e.g.: main
+func (SQLite) HasTableQuery(schema, tbl string) (string, []any) {
+	return "SELECT EXISTS (SELECT 1 FROM " + SQLite{}.Quote(sqliteSchema(schema)) + ".sqlite_master WHERE type = 'table' AND name = ?)",
+		[]any{tbl}
+}
+
+// HasColumnQuery checks column existence via pragma_table_info, schema is the database name e.g.: main
+func (SQLite) HasColumnQuery(schema, tbl, col string) (string, []any) {
+	return "SELECT EXISTS (SELECT 1 FROM pragma_table_info(?, ?) WHERE name = ?)", []any{tbl, sqliteSchema(schema), col}
+}
+
+// SetLocal returns an empty query as SQLite pragmas aren't transaction scoped
+func (SQLite) SetLocal(string, string) (string, []any) {
+	return "", nil
+}
+
+// Deferrable returns an empty string as SQLite transactions are serializable by design
+func (SQLite) Deferrable() string {
+	return ""
+}
+
+// SchemaGrammar returns DDL features of SQLite
+func (SQLite) SchemaGrammar() SchemaGrammar {
+	return SchemaGrammar{
+		DefaultSchema:   sqliteMainSchema,
+		Serial:          TypeInt,
+		BigSerial:       TypeInt,
+		CurrentDateTime: "CURRENT_TIMESTAMP",
+	}
+}
+
+const sqliteMainSchema = "main"
+
+// sqliteSchema falls back to main database if schema is not set
+func sqliteSchema(schema string) string {
+	if schema == "" {
+		return sqliteMainSchema
+	}
+
+	return schema
+}
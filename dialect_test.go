@@ -0,0 +1,70 @@
+package buildsqlx
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialect_Default(t *testing.T) {
+	pgDb := NewDb(NewConnectionFromDb(&sql.DB{}))
+	require.Equal(t, "postgres", pgDb.Dialect().Name())
+
+	q := pgDb.Table(UsersTable).Select("name").Where("id", "=", 1).AndWhereIn("points", []int64{1, 2}).Offset(5)
+	require.Equal(t, `SELECT name FROM "test_users" WHERE id = $1 AND points IN ($2, $3) OFFSET 5`, q.Builder.buildSelect())
+	require.Equal(t, " ON CONFLICT(id) DO UPDATE SET name = excluded.name, points = excluded.points",
+		pgDb.Dialect().Upsert("id", []string{"name", "points"}))
+
+	query, args := pgDb.Dialect().SetLocal("statement_timeout", "5s")
+	require.Equal(t, "SELECT set_config($1, $2, true)", query)
+	require.Equal(t, []any{"statement_timeout", "5s"}, args)
+	require.Equal(t, "SET TRANSACTION DEFERRABLE", pgDb.Dialect().Deferrable())
+}
+
+func TestDialect_MySQL(t *testing.T) {
+	mysqlDb := NewDb(NewConnectionFromDb(&sql.DB{}), WithDialect(MySQL{}))
+	require.Equal(t, "mysql", mysqlDb.Dialect().Name())
+
+	q := mysqlDb.Table(UsersTable).Select("name").Where("id", "=", 1).AndWhereIn("points", []int64{1, 2}).
+		InRandomOrder().Offset(5)
+	require.Equal(t, "SELECT name FROM `test_users` WHERE id = ? AND points IN (?, ?) ORDER BY RAND() LIMIT 18446744073709551615 OFFSET 5",
+		q.Builder.buildSelect())
+
+	q = mysqlDb.Table(UsersTable).CrossJoin(PostsTable).Limit(10)
+	require.Equal(t, "SELECT * FROM `test_users` CROSS JOIN test_posts  LIMIT 10", q.Builder.buildSelect())
+
+	require.Equal(t, " ON DUPLICATE KEY UPDATE name = VALUES(name), points = VALUES(points)",
+		mysqlDb.Dialect().Upsert("id", []string{"name", "points"}))
+	require.False(t, mysqlDb.Dialect().Returning())
+	require.Empty(t, mysqlDb.Dialect().CopyIn(UsersTable, []string{"name"}))
+
+	query, args := mysqlDb.Dialect().HasTableQuery("app", UsersTable)
+	require.Equal(t, "SELECT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?)", query)
+	require.Equal(t, []any{"app", UsersTable}, args)
+
+	query, _ = mysqlDb.Dialect().SetLocal("lock_timeout", "1s")
+	require.Empty(t, query)
+	require.Empty(t, mysqlDb.Dialect().Deferrable())
+}
+
+func TestDialect_SQLite(t *testing.T) {
+	sqliteDb := NewDb(NewConnectionFromDb(&sql.DB{}), WithDialect(SQLite{}))
+	require.Equal(t, "sqlite", sqliteDb.Dialect().Name())
+
+	q := sqliteDb.Table(UsersTable).Select("name").Where("id", "=", 1).OrWhereIn("points", []int64{1, 2}).
+		InRandomOrder().Offset(5).LockForUpdate()
+	require.Equal(t, `SELECT name FROM "test_users" WHERE id = ? OR points IN (?, ?) ORDER BY random() LIMIT -1 OFFSET 5`,
+		q.Builder.buildSelect())
+
+	require.Equal(t, " ON CONFLICT(id) DO UPDATE SET name = excluded.name",
+		sqliteDb.Dialect().Upsert("id", []string{"name"}))
+
+	query, args := sqliteDb.Dialect().HasTableQuery("", UsersTable)
+	require.Equal(t, `SELECT EXISTS (SELECT 1 FROM "main".sqlite_master WHERE type = 'table' AND name = ?)`, query)
+	require.Equal(t, []any{UsersTable}, args)
+
+	query, args = sqliteDb.Dialect().HasColumnQuery("main", UsersTable, "name")
+	require.Equal(t, "SELECT EXISTS (SELECT 1 FROM pragma_table_info(?, ?) WHERE name = ?)", query)
+	require.Equal(t, []any{UsersTable, "main", "name"}, args)
+}
@@ -1,16 +1,15 @@
 package buildsqlx
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
 	"reflect"
 	"strconv"
 	"strings"
 
 	"github.com/fatih/structs"
-	"github.com/lib/pq"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -30,35 +29,25 @@ type EachToStructFunc func(rows *sql.Rows) error
 
 // ScanStruct scans query into specific struct
 func (r *DB) ScanStruct(src any) error {
+	return r.ScanStructCtx(context.Background(), src)
+}
+
+// ScanStructCtx scans query into specific struct with the given context
+func (r *DB) ScanStructCtx(ctx context.Context, src any) error {
 	if reflect.ValueOf(src).IsNil() {
 		return fmt.Errorf("cannot decode into nil type %T", src)
 	}
 
-	sqlBuilder := r.Builder
-	if sqlBuilder.table == "" {
-		return errTableCallBeforeOp
-	}
-
+	// limit the copy, so the query can be reused
+	sqlBuilder := r.Builder.clone()
 	sqlBuilder.limit = 1
-	query := ""
-	if len(sqlBuilder.union) > 0 { // got union - need different logic to glue
-		for _, uBuilder := range sqlBuilder.union {
-			query += uBuilder + " UNION "
-
-			if sqlBuilder.isUnionAll {
-				query += "ALL "
-			}
-		}
-
-		query += sqlBuilder.buildSelect()
-		// clean union (all) after ensuring selects are built
-		r.Builder.union = []string{}
-		r.Builder.isUnionAll = false
-	} else { // std builder
-		query = sqlBuilder.buildSelect()
+	query, args, err := sqlBuilder.selectSQL()
+	if err != nil {
+		return err
 	}
 
-	rows, err := r.Sql().Query(query, prepareValues(r.Builder.whereBindings)...)
+	e := r.event(OpSelect, sqlBuilder.table, query, args)
+	rows, done, err := queryHooked(r.hookCtx(ctx), r.reader(ctx), r.allHooks(), e)
 	if err != nil {
 		return err
 	}
@@ -71,9 +60,10 @@ func (r *DB) ScanStruct(src any) error {
 	// resource is the actual value that ptr points to.
 	resource := reflect.ValueOf(src).Elem()
 	if err = validateFields(resource, src, columns); err != nil {
-		return err
+		return done(0, err)
 	}
 
+	var n int64
 	for rows.Next() {
 		for i := range columns {
 			valuePtrs[i] = &values[i]
@@ -81,7 +71,7 @@ func (r *DB) ScanStruct(src any) error {
 
 		err = rows.Scan(valuePtrs...)
 		if err != nil {
-			return err
+			return done(n, err)
 		}
 
 		for i, col := range columns {
@@ -90,50 +80,41 @@ func (r *DB) ScanStruct(src any) error {
 		}
 
 		src = resource
+		n++
 	}
 
-	return nil
+	return done(n, nil)
 }
 
 // EachToStruct scans query into specific struct per row with iterative behaviour
 func (r *DB) EachToStruct(fn EachToStructFunc) error {
-	sqlBuilder := r.Builder
-	if sqlBuilder.table == "" {
-		return errTableCallBeforeOp
-	}
-
-	query := ""
-	if len(sqlBuilder.union) > 0 { // got union - need different logic to glue
-		for _, uBuilder := range sqlBuilder.union {
-			query += uBuilder + " UNION "
-
-			if sqlBuilder.isUnionAll {
-				query += "ALL "
-			}
-		}
+	return r.EachToStructCtx(context.Background(), fn)
+}
 
-		query += sqlBuilder.buildSelect()
-		// clean union (all) after ensuring selects are built
-		r.Builder.union = []string{}
-		r.Builder.isUnionAll = false
-	} else { // std builder
-		query = sqlBuilder.buildSelect()
+// EachToStructCtx scans query into specific struct per row with iterative behaviour with the given context
+func (r *DB) EachToStructCtx(ctx context.Context, fn EachToStructFunc) error {
+	query, args, err := r.Builder.selectSQL()
+	if err != nil {
+		return err
 	}
 
-	rows, err := r.Sql().Query(query, prepareValues(r.Builder.whereBindings)...)
+	e := r.event(OpSelect, r.Builder.table, query, args)
+	rows, done, err := queryHooked(r.hookCtx(ctx), r.reader(ctx), r.allHooks(), e)
 	if err != nil {
 		return err
 	}
 
+	var n int64
 	for {
 		err = fn(rows)
 		if errors.Is(err, ErrNoMoreRows) {
-			return nil
+			return done(n, nil)
 		}
 
 		if err != nil {
-			return err
+			return done(n, err)
 		}
+		n++
 	}
 }
 
@@ -208,7 +189,11 @@ func setValue(field reflect.Value, val any) {
 	if field.Kind() == reflect.Ptr {
 		newVal := reflect.New(field.Type().Elem())
 		if val != nil {
-			newVal.Elem().Set(reflect.ValueOf(val))
+			if rv := reflect.ValueOf(val); rv.Type().AssignableTo(newVal.Elem().Type()) {
+				newVal.Elem().Set(rv)
+			} else { // e.g. []byte returned by MySQL driver for *string field
+				setValue(newVal.Elem(), val)
+			}
 		}
 		field.Set(newVal)
 
@@ -226,8 +211,11 @@ func setValue(field reflect.Value, val any) {
 		field.SetFloat(v)
 	case uint64:
 		field.SetUint(v)
-	case nil:
-		field.SetPointer(nil)
+	case []byte:
+		setBytesValue(field, v)
+	case nil: // e.g. LAG/LEAD out of the partition
+		field.Set(reflect.Zero(field.Type()))
+		return
 	}
 
 	if reflect.TypeOf(val).Kind() == reflect.Ptr {
@@ -235,6 +223,34 @@ func setValue(field reflect.Value, val any) {
 	}
 }
 
+// setBytesValue converts raw bytes, that drivers like MySQL return for text protocol, to the field kind
+func setBytesValue(field reflect.Value, v []byte) {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(string(v))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			field.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			field.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(string(v), 64); err == nil {
+			field.SetFloat(f)
+		}
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(string(v)); err == nil {
+			field.SetBool(b)
+		}
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.Uint8 {
+			field.SetBytes(append([]byte(nil), v...))
+		}
+	}
+}
+
 func validateFields(resource reflect.Value, src any, columns []string) error {
 	for _, col := range columns {
 		foundColByTag := false
@@ -264,50 +280,175 @@ func validateFields(resource reflect.Value, src any, columns []string) error {
 func prepareValues(values []map[string]any) []any {
 	var vls []any
 	for _, v := range values {
-		_, vals, _ := prepareBindings(v)
-		vls = append(vls, vals...)
+		for _, value := range v {
+			switch vi := value.(type) {
+			case whereGroup:
+				vls = append(vls, prepareValues(vi)...)
+				continue
+			case whereColumn:
+				continue
+			case subQuery:
+				vls = append(vls, vi.b.queryArgs()...)
+				continue
+			case *rawExpr:
+				vls = append(vls, vi.bound()...)
+				continue
+			case between:
+				vls = append(vls, prepareValue(vi[0])...)
+				vls = append(vls, prepareValue(vi[1])...)
+				continue
+			case nullCheck:
+				continue
+			}
+
+			vls = append(vls, prepareValue(value)...)
+		}
 	}
 	return vls
 }
 
+// args returns values bound to select stmt in order of their placeholders
+func (r *builder) args() []any {
+	if r.raw != nil {
+		return r.raw.bound()
+	}
+
+	var args []any
+	if r.selectRaw != nil {
+		args = append(args, r.selectRaw.bound()...)
+	}
+
+	for _, sub := range r.selectSubs {
+		args = append(args, sub.b.queryArgs()...)
+	}
+
+	if r.fromSub != nil {
+		args = append(args, r.fromSub.b.queryArgs()...)
+	}
+
+	return append(args, r.clauseArgs()...)
+}
+
+// clauseArgs returns values bound to join, where, having and order by clauses in order of their placeholders
+func (r *builder) clauseArgs() []any {
+	var args []any
+	for _, j := range r.join {
+		args = append(args, prepareValues(j.on)...)
+	}
+
+	args = append(args, prepareValues(r.whereBindings)...)
+	args = append(args, prepareValues(r.havingBindings)...)
+
+	// raw expression is ordered by only if there are no columns to order by
+	if len(r.orderBy) == 0 && r.orderByRaw != nil {
+		args = append(args, r.orderByRaw.bound()...)
+	}
+
+	return args
+}
+
+// queryArgs returns values bound to WITH clause, selects glued by Union/UnionAll and select stmt
+// in order of their placeholders
+func (r *builder) queryArgs() []any {
+	args := r.withArgs()
+	for _, part := range r.union {
+		args = append(args, part.args()...)
+	}
+
+	return append(args, r.args()...)
+}
+
 // buildSelect constructs a query for select statement
 func (r *builder) buildSelect() string {
-	query := `SELECT ` + strings.Join(r.columns, `, `) + ` FROM "` + r.table + `"`
+	i := r.startBindingsAt
+	return r.compileSelect(&i)
+}
+
+// compileSelect constructs a query for select statement numbering placeholders from i,
+// so the query can be nested into another one
+func (r *builder) compileSelect(i *int) string {
+	if r.raw != nil {
+		return r.raw.compile(r.dialect, i)
+	}
 
-	return query + r.buildClauses()
+	columns := strings.Join(r.columns, `, `)
+	if r.selectRaw != nil {
+		columns = strings.Join(append([]string{r.selectRaw.compile(r.dialect, i)}, r.columns...), `, `)
+	}
+	for _, sub := range r.selectSubs {
+		columns += `, (` + sub.b.compileQuery(i) + `) AS ` + r.dialect.Quote(sub.alias)
+	}
+
+	from := r.dialect.Quote(r.table)
+	if r.fromSub != nil {
+		from = `(` + r.fromSub.b.compileQuery(i) + `) AS ` + from
+	}
+
+	return `SELECT ` + columns + ` FROM ` + from + r.compileClauses(i)
+}
+
+// buildQuery constructs a query for select statement with WITH clause and selects of Union/UnionAll if any
+func (r *builder) buildQuery() string {
+	i := r.startBindingsAt
+	return r.compileQuery(&i)
+}
+
+// compileQuery constructs a query for select statement with WITH clause and selects of Union/UnionAll if any
+// numbering placeholders from i
+func (r *builder) compileQuery(i *int) string {
+	query := r.compileWith(i)
+	for _, part := range r.union {
+		query += part.compileSelect(i) + " UNION "
+
+		if r.isUnionAll {
+			query += "ALL "
+		}
+	}
+
+	return query + r.compileSelect(i)
 }
 
 // builds query string clauses
 func (r *builder) buildClauses() string {
+	i := r.startBindingsAt
+	return r.compileClauses(&i)
+}
+
+// compileClauses constructs query clauses numbering placeholders from i
+func (r *builder) compileClauses(i *int) string {
 	clauses := ""
 	for _, j := range r.join {
-		clauses += j
+		clauses += j.clause
+		if len(j.on) > 0 {
+			clauses += composeConditions(r.dialect, j.on, i) + " "
+		}
 	}
 
 	// build where clause
 	if len(r.whereBindings) > 0 {
-		clauses += composeWhere(r.whereBindings, r.startBindingsAt)
-	} else { // std without bindings todo: change all to bindings
-		clauses += r.where
+		clauses += sqlKeyWordWhere + composeConditions(r.dialect, r.whereBindings, i)
 	}
 
 	if r.groupBy != "" {
 		clauses += " GROUP BY " + r.groupBy
 	}
 
-	if r.having != "" {
-		clauses += " HAVING " + r.having
+	if len(r.havingBindings) > 0 {
+		clauses += " HAVING " + composeConditions(r.dialect, r.havingBindings, i)
 	}
 
-	clauses += composeOrderBy(r.orderBy, r.orderByRaw)
+	if len(r.windows) > 0 {
+		windows := make([]string, 0, len(r.windows))
+		for _, w := range r.windows {
+			windows = append(windows, w.name+" AS ("+w.window+")")
+		}
 
-	if r.limit > 0 {
-		clauses += " LIMIT " + strconv.FormatInt(r.limit, 10)
+		clauses += " WINDOW " + strings.Join(windows, ", ")
 	}
 
-	if r.offset > 0 {
-		clauses += " OFFSET " + strconv.FormatInt(r.offset, 10)
-	}
+	clauses += composeOrderBy(r.dialect, r.orderBy, r.orderByRaw, i)
+
+	clauses += r.dialect.LimitOffset(r.limit, r.offset)
 
 	if r.lockForUpdate != nil {
 		clauses += *r.lockForUpdate
@@ -316,29 +457,37 @@ func (r *builder) buildClauses() string {
 	return clauses
 }
 
-// composes WHERE clause string for particular query stmt
-func composeWhere(whereBindings []map[string]any, startedAt int) string {
-	where := " WHERE "
-	i := startedAt
+// composes conditions numbering placeholders from i, which is advanced by the number of bound values,
+// so conditions of nested groups continue the numbering of the outer ones
+func composeConditions(d Dialect, whereBindings []map[string]any, i *int) string {
+	where := ""
 	for _, m := range whereBindings {
 		for k, v := range m {
 			// operand >= $i
 			switch vi := v.(type) {
+			case whereGroup:
+				where += k + "(" + composeConditions(d, vi, i) + ")"
+			case whereColumn:
+				where += k + " " + string(vi)
+			case subQuery:
+				where += k + " (" + vi.b.compileQuery(i) + ")"
+			case *rawExpr:
+				where += k + vi.compile(d, i)
+			case between:
+				where += k + " " + d.Placeholder(*i) + sqlKeyWordAnd + d.Placeholder(*i+1)
+				*i += 2
+			case nullCheck:
+				where += k + " " + string(vi)
 			case []any:
 				placeholders := make([]string, 0, len(vi))
 				for range vi {
-					placeholders = append(placeholders, "$"+strconv.Itoa(i))
-					i++
+					placeholders = append(placeholders, d.Placeholder(*i))
+					*i++
 				}
 				where += k + " (" + strings.Join(placeholders, ", ") + ")"
 			default:
-				if strings.Contains(k, sqlOperatorIs) || strings.Contains(k, sqlOperatorBetween) {
-					where += k + " " + vi.(string)
-					break
-				}
-
-				where += k + " $" + strconv.Itoa(i)
-				i++
+				where += k + " " + d.Placeholder(*i)
+				*i++
 			}
 		}
 	}
@@ -346,7 +495,7 @@ func composeWhere(whereBindings []map[string]any, startedAt int) string {
 }
 
 // composers ORDER BY clause string for particular query stmt
-func composeOrderBy(orderBy []map[string]string, orderByRaw *string) string {
+func composeOrderBy(d Dialect, orderBy []map[string]string, orderByRaw *rawExpr, i *int) string {
 	if len(orderBy) > 0 {
 		orderStr := ""
 		for _, m := range orderBy {
@@ -360,27 +509,29 @@ func composeOrderBy(orderBy []map[string]string, orderByRaw *string) string {
 		}
 		return orderStr
 	} else if orderByRaw != nil {
-		return " ORDER BY " + *orderByRaw
+		return " ORDER BY " + orderByRaw.compile(d, i)
 	}
 	return ""
 }
 
 // Insert inserts one row with param bindings for struct
 func (r *DB) Insert(data any) error {
+	return r.InsertCtx(context.Background(), data)
+}
+
+// InsertCtx inserts one row with param bindings for struct with the given context
+func (r *DB) InsertCtx(ctx context.Context, data any) error {
 	if r.Txn != nil {
-		return r.Txn.Insert(data)
+		return r.txn().InsertCtx(ctx, data)
 	}
 
 	bldr := r.Builder
-	if bldr.table == "" {
-		return errTableCallBeforeOp
+	query, values, err := bldr.insertSQL(data)
+	if err != nil {
+		return err
 	}
 
-	columns, values, bindings := prepareBindingsForStruct(data)
-
-	query := `INSERT INTO "` + bldr.table + `" (` + strings.Join(columns, `, `) + `) VALUES(` + strings.Join(bindings, `, `) + `)`
-
-	_, err := r.Sql().Exec(query, values...)
+	_, err = r.exec(ctx, OpInsert, bldr.table, query, values...)
 	if err != nil {
 		return err
 	}
@@ -391,20 +542,23 @@ func (r *DB) Insert(data any) error {
 // Insert inserts one row with param bindings from struct
 // in transaction context
 func (r *Txn) Insert(data any) error {
+	return r.InsertCtx(context.Background(), data)
+}
+
+// InsertCtx inserts one row with param bindings from struct
+// in transaction context with the given context
+func (r *Txn) InsertCtx(ctx context.Context, data any) error {
 	if r.Tx == nil {
 		return errTransactionModeWithoutTx
 	}
 
 	bldr := r.Builder
-	if bldr.table == "" {
-		return errTableCallBeforeOp
+	query, values, err := bldr.insertSQL(data)
+	if err != nil {
+		return err
 	}
 
-	columns, values, bindings := prepareBindingsForStruct(data)
-
-	query := `INSERT INTO "` + bldr.table + `" (` + strings.Join(columns, `, `) + `) VALUES(` + strings.Join(bindings, `, `) + `)`
-
-	_, err := r.Tx.Exec(query, values...)
+	_, err = r.exec(ctx, OpInsert, query, values...)
 	if err != nil {
 		return err
 	}
@@ -414,47 +568,72 @@ func (r *Txn) Insert(data any) error {
 
 // InsertGetId inserts one row with param bindings and returning id
 func (r *DB) InsertGetId(data any) (uint64, error) {
+	return r.InsertGetIdCtx(context.Background(), data)
+}
+
+// InsertGetIdCtx inserts one row with param bindings and returning id with the given context
+func (r *DB) InsertGetIdCtx(ctx context.Context, data any) (uint64, error) {
 	if r.Txn != nil {
-		return r.Txn.InsertGetId(data)
+		return r.txn().InsertGetIdCtx(ctx, data)
 	}
 
 	bldr := r.Builder
-	if bldr.table == "" {
-		return 0, errTableCallBeforeOp
+	query, values, err := bldr.insertSQL(data)
+	if err != nil {
+		return 0, err
 	}
 
-	columns, values, bindings := prepareBindingsForStruct(data)
+	if !bldr.dialect.Returning() {
+		res, err := r.exec(ctx, OpInsert, bldr.table, query, values...)
+		if err != nil {
+			return 0, err
+		}
 
-	query := `INSERT INTO "` + bldr.table + `" (` + strings.Join(columns, `, `) + `) VALUES(` + strings.Join(bindings, `, `) + `) RETURNING id`
+		return lastInsertId(res)
+	}
 
 	var id uint64
-	err := r.Sql().QueryRow(query, values...).Scan(&id)
+	e := r.event(OpInsert, bldr.table, query+` RETURNING id`, values)
+	err = queryRowHooked(r.hookCtx(ctx), r.writer(), r.allHooks(), e, &id)
 
 	if err != nil {
 		return 0, err
 	}
 
+	r.Conn.wrote(ctx)
 	return id, nil
 }
 
 // InsertGetId inserts one row with param bindings and returning id
 // in transaction context
 func (r *Txn) InsertGetId(data any) (uint64, error) {
+	return r.InsertGetIdCtx(context.Background(), data)
+}
+
+// InsertGetIdCtx inserts one row with param bindings and returning id
+// in transaction context with the given context
+func (r *Txn) InsertGetIdCtx(ctx context.Context, data any) (uint64, error) {
 	if r.Tx == nil {
 		return 0, errTransactionModeWithoutTx
 	}
 
 	bldr := r.Builder
-	if bldr.table == "" {
-		return 0, errTableCallBeforeOp
+	query, values, err := bldr.insertSQL(data)
+	if err != nil {
+		return 0, err
 	}
 
-	columns, values, bindings := prepareBindingsForStruct(data)
+	if !bldr.dialect.Returning() {
+		res, err := r.exec(ctx, OpInsert, query, values...)
+		if err != nil {
+			return 0, err
+		}
 
-	query := `INSERT INTO "` + bldr.table + `" (` + strings.Join(columns, `, `) + `) VALUES(` + strings.Join(bindings, `, `) + `) RETURNING id`
+		return lastInsertId(res)
+	}
 
 	var id uint64
-	err := r.Tx.QueryRow(query, values...).Scan(&id)
+	err = queryRowHooked(r.hookCtx(ctx), r.Tx, r.hooks, r.event(OpInsert, query+` RETURNING id`, values), &id)
 
 	if err != nil {
 		return 0, err
@@ -463,6 +642,38 @@ func (r *Txn) InsertGetId(data any) (uint64, error) {
 	return id, nil
 }
 
+// InsertUsing inserts rows selected by q into columns returning affected rows, ex.:
+// Table("archive").InsertUsing([]string{"id", "title"}, db.Table("posts").Select("id", "title").Where("id", "<", 100))
+func (r *DB) InsertUsing(columns []string, q *DB) (int64, error) {
+	return r.InsertUsingCtx(context.Background(), columns, q)
+}
+
+// InsertUsingCtx inserts rows selected by q into columns returning affected rows with the given context
+func (r *DB) InsertUsingCtx(ctx context.Context, columns []string, q *DB) (int64, error) {
+	bldr := r.Builder
+	query, args, err := bldr.insertUsingSQL(columns, q.Builder)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := r.exec(ctx, OpInsert, bldr.table, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// lastInsertId gets id generated by db e.g. LAST_INSERT_ID() for MySQL
+func lastInsertId(res sql.Result) (uint64, error) {
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(id), nil
+}
+
 func prepareValuesForStruct(value reflect.Value) []any {
 	var values []any
 	switch value.Kind() {
@@ -470,14 +681,24 @@ func prepareValuesForStruct(value reflect.Value) []any {
 		values = append(values, value.String())
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		values = append(values, strconv.FormatInt(value.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		values = append(values, strconv.FormatUint(value.Uint(), 10))
 	case reflect.Float32, reflect.Float64:
-		values = append(values, fmt.Sprintf("%g", value.Float()))
+		values = append(values, strconv.FormatFloat(value.Float(), 'g', -1, value.Type().Bits()))
 	case reflect.Ptr:
 		if value.IsNil() {
 			values = append(values, nil)
 		} else {
 			values = prepareValuesForStruct(value.Elem())
 		}
+	case reflect.Bool:
+		values = append(values, value.Bool())
+	default: // time.Time, []byte, driver.Valuer etc. are converted by driver
+		if value.CanInterface() {
+			values = append(values, value.Interface())
+		} else {
+			values = append(values, nil)
+		}
 	}
 
 	return values
@@ -486,42 +707,30 @@ func prepareValuesForStruct(value reflect.Value) []any {
 func prepareValue(value any) []any {
 	var values []any
 	switch v := value.(type) {
-	case string:
-		values = append(values, v)
-	case int:
-		values = append(values, strconv.FormatInt(int64(v), 10))
-	case float64:
-		values = append(values, fmt.Sprintf("%g", v))
-	case int64:
-		values = append(values, strconv.FormatInt(v, 10))
-	case uint64:
+	case uint64: // database/sql doesn't support uint64 values with high bit set
 		values = append(values, strconv.FormatUint(v, 10))
 	case []any:
 		for _, vi := range v {
 			values = append(values, prepareValue(vi)...)
 		}
-	case nil:
-		values = append(values, nil)
+	default: // int, bool, time.Time, float32 etc. are converted by driver
+		values = append(values, v)
 	}
 
 	return values
 }
 
 // prepareBindings prepares slices to split in favor of INSERT sql statement
-func prepareBindings(data map[string]any) (columns []string, values []any, bindings []string) {
+func prepareBindings(d Dialect, data map[string]any) (columns []string, values []any, bindings []string) {
 	i := 1
 	for column, value := range data {
-		if strings.Contains(column, sqlOperatorIs) || strings.Contains(column, sqlOperatorBetween) {
-			continue
-		}
-
 		columns = append(columns, column)
 		pValues := prepareValue(value)
 		if len(pValues) > 0 {
 			values = append(values, pValues...)
 
 			for range pValues {
-				bindings = append(bindings, "$"+strconv.FormatInt(int64(i), 10))
+				bindings = append(bindings, d.Placeholder(i))
 				i++
 			}
 		}
@@ -531,7 +740,7 @@ func prepareBindings(data map[string]any) (columns []string, values []any, bindi
 }
 
 // prepareBindingsForStruct prepares all bindings for SQL-query
-func prepareBindingsForStruct(data any) (columns []string, values []any, bindings []string) {
+func prepareBindingsForStruct(d Dialect, data any) (columns []string, values []any, bindings []string) {
 	j := 1
 	resource := reflect.ValueOf(data)
 	t := reflect.TypeOf(data)
@@ -539,17 +748,13 @@ func prepareBindingsForStruct(data any) (columns []string, values []any, binding
 		value := resource.Field(i)
 		col := getColumn(t.Field(i))
 
-		if strings.Contains(col, sqlOperatorIs) || strings.Contains(col, sqlOperatorBetween) {
-			continue
-		}
-
 		columns = append(columns, col)
 		pValues := prepareValuesForStruct(value)
 		if len(pValues) > 0 {
 			values = append(values, pValues...)
 
 			for range pValues {
-				bindings = append(bindings, "$"+strconv.FormatInt(int64(j), 10))
+				bindings = append(bindings, d.Placeholder(j))
 				j++
 			}
 		}
@@ -594,44 +799,125 @@ func getFieldValue(src any, col string) any {
 
 // InsertBatch inserts multiple rows based on transaction
 func (r *DB) InsertBatch(data any) error {
+	return r.InsertBatchCtx(context.Background(), data)
+}
+
+// InsertBatchCtx inserts multiple rows based on transaction with the given context,
+// rows are inserted in the current transaction in transaction mode
+func (r *DB) InsertBatchCtx(ctx context.Context, data any) error {
 	bldr := r.Builder
 	if bldr.table == "" {
 		return errTableCallBeforeOp
 	}
 
-	txn, err := r.Sql().Begin()
+	if r.Txn != nil {
+		return r.insertBatch(ctx, r.Txn.Tx, data)
+	}
+
+	txn, err := r.Sql().BeginTx(ctx, nil)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
+	if err = r.insertBatch(ctx, txn, data); err != nil {
+		_ = txn.Rollback()
+		return err
+	}
+
+	if err = txn.Commit(); err != nil {
+		return err
+	}
+
+	r.Conn.wrote(ctx)
+	return nil
+}
+
+// insertBatch inserts multiple rows in txn by COPY if dialect supports it or by multi-row INSERT otherwise
+func (r *DB) insertBatch(ctx context.Context, txn *sql.Tx, data any) error {
+	bldr := r.Builder
 	iSlice := anySlice(data)
 	columns, values := prepareInsertBatchForStructs(iSlice)
 
-	stmt, err := txn.Prepare(pq.CopyIn(bldr.table, columns...))
+	hooks := r.allHooks()
+	copyStmt := bldr.dialect.CopyIn(bldr.table, columns)
+	if copyStmt != "" {
+		e := bldr.event(OpInsert, bldr.table, copyStmt, nil)
+		e.InTx = true
+		return copyInBatch(r.hookCtx(ctx), txn, hooks, e, values)
+	}
+
+	return insertMultiRows(r.hookCtx(ctx), txn, hooks, bldr, columns, values)
+}
+
+// copyInBatch streams rows via COPY stmt calling hooks around the whole stream, as rows are sent one by one
+// the event has no Args
+func copyInBatch(ctx context.Context, txn *sql.Tx, hooks []Hook, e *QueryEvent, values [][]any) (err error) {
+	ctx = beforeQuery(ctx, hooks, e)
+	defer func() {
+		if err == nil {
+			e.RowsAffected = int64(len(values))
+		}
+		afterQuery(ctx, hooks, e, err)
+	}()
+
+	stmt, err := txn.PrepareContext(ctx, e.SQL)
 	if err != nil {
 		return err
 	}
 
 	for _, value := range values {
-		_, err = stmt.Exec(value...)
+		_, err = stmt.ExecContext(ctx, value...)
 		if err != nil {
+			_ = stmt.Close()
 			return err
 		}
 	}
 
-	_, err = stmt.Exec()
+	_, err = stmt.ExecContext(ctx)
 	if err != nil {
+		_ = stmt.Close()
 		return err
 	}
 
-	err = stmt.Close()
-	if err != nil {
-		return err
+	return stmt.Close()
+}
+
+// insertMultiRows inserts rows via INSERT ... VALUES (...), (...) stmts
+// split by chunks to fit the max number of bind parameters
+func insertMultiRows(ctx context.Context, txn *sql.Tx, hooks []Hook, bldr *builder, columns []string, values [][]any) error {
+	if len(values) == 0 {
+		return nil
 	}
 
-	err = txn.Commit()
-	if err != nil {
-		return err
+	perStmt := len(values)
+	if maxRows := bldr.dialect.MaxPlaceholders() / len(columns); maxRows < perStmt {
+		perStmt = maxRows
+	}
+
+	for start := 0; start < len(values); start += perStmt {
+		end := start + perStmt
+		if end > len(values) {
+			end = len(values)
+		}
+
+		rows := make([]string, 0, end-start)
+		args := make([]any, 0, (end-start)*len(columns))
+		for _, value := range values[start:end] {
+			bindings := make([]string, len(value))
+			for i := range value {
+				bindings[i] = bldr.dialect.Placeholder(len(args) + i + 1)
+			}
+
+			rows = append(rows, `(`+strings.Join(bindings, `, `)+`)`)
+			args = append(args, value...)
+		}
+
+		query := `INSERT INTO ` + bldr.dialect.Quote(bldr.table) + ` (` + strings.Join(columns, `, `) + `) VALUES ` + strings.Join(rows, `, `)
+		e := bldr.event(OpInsert, bldr.table, query, args)
+		e.InTx = true
+		if _, err := execHooked(ctx, txn, hooks, e); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -709,34 +995,23 @@ func anySlice(slice any) []any {
 // Update builds an UPDATE sql stmt with corresponding where/from clauses if stated
 // returning affected rows
 func (r *DB) Update(data any) (int64, error) {
+	return r.UpdateCtx(context.Background(), data)
+}
+
+// UpdateCtx builds an UPDATE sql stmt with corresponding where/from clauses if stated
+// returning affected rows with the given context
+func (r *DB) UpdateCtx(ctx context.Context, data any) (int64, error) {
 	if r.Txn != nil {
-		return r.Txn.Update(data)
+		return r.txn().UpdateCtx(ctx, data)
 	}
 
 	bldr := r.Builder
-	if bldr.table == "" {
-		return 0, errTableCallBeforeOp
-	}
-
-	columns, values, bindings := prepareBindingsForStruct(data)
-	setVal := ""
-	l := len(columns)
-	for k, col := range columns {
-		setVal += col + " = " + bindings[k]
-		if k < l-1 {
-			setVal += ", "
-		}
-	}
-
-	query := `UPDATE "` + r.Builder.table + `" SET ` + setVal
-	if r.Builder.from != "" {
-		query += " FROM " + r.Builder.from
+	query, values, err := bldr.updateSQL(data)
+	if err != nil {
+		return 0, err
 	}
 
-	r.Builder.startBindingsAt = l + 1
-	query += r.Builder.buildClauses()
-	values = append(values, prepareValues(r.Builder.whereBindings)...)
-	res, err := r.Sql().Exec(query, values...)
+	res, err := r.exec(ctx, OpUpdate, bldr.table, query, values...)
 	if err != nil {
 		return 0, err
 	}
@@ -747,34 +1022,23 @@ func (r *DB) Update(data any) (int64, error) {
 // Update builds an UPDATE sql stmt with corresponding where/from clauses if stated
 // returning affected rows
 func (r *Txn) Update(data any) (int64, error) {
+	return r.UpdateCtx(context.Background(), data)
+}
+
+// UpdateCtx builds an UPDATE sql stmt with corresponding where/from clauses if stated
+// returning affected rows with the given context
+func (r *Txn) UpdateCtx(ctx context.Context, data any) (int64, error) {
 	if r.Tx == nil {
 		return 0, errTransactionModeWithoutTx
 	}
 
 	bldr := r.Builder
-	if bldr.table == "" {
-		return 0, errTableCallBeforeOp
-	}
-
-	columns, values, bindings := prepareBindingsForStruct(data)
-	setVal := ""
-	l := len(columns)
-	for k, col := range columns {
-		setVal += col + " = " + bindings[k]
-		if k < l-1 {
-			setVal += ", "
-		}
-	}
-
-	query := `UPDATE "` + r.Builder.table + `" SET ` + setVal
-	if r.Builder.from != "" {
-		query += " FROM " + r.Builder.from
+	query, values, err := bldr.updateSQL(data)
+	if err != nil {
+		return 0, err
 	}
 
-	r.Builder.startBindingsAt = l + 1
-	query += r.Builder.buildClauses()
-	values = append(values, prepareValues(r.Builder.whereBindings)...)
-	res, err := r.Tx.Exec(query, values...)
+	res, err := r.exec(ctx, OpUpdate, query, values...)
 	if err != nil {
 		return 0, err
 	}
@@ -785,18 +1049,23 @@ func (r *Txn) Update(data any) (int64, error) {
 // Delete builds a DELETE stmt with corresponding where clause if stated
 // returning affected rows
 func (r *DB) Delete() (int64, error) {
+	return r.DeleteCtx(context.Background())
+}
+
+// DeleteCtx builds a DELETE stmt with corresponding where clause if stated
+// returning affected rows with the given context
+func (r *DB) DeleteCtx(ctx context.Context) (int64, error) {
 	if r.Txn != nil {
-		return r.Txn.Delete()
+		return r.txn().DeleteCtx(ctx)
 	}
 
 	bldr := r.Builder
-	if bldr.table == "" {
-		return 0, errTableCallBeforeOp
+	query, args, err := bldr.deleteSQL()
+	if err != nil {
+		return 0, err
 	}
 
-	query := `DELETE FROM "` + r.Builder.table + `"`
-	query += r.Builder.buildClauses()
-	res, err := r.Sql().Exec(query, prepareValues(r.Builder.whereBindings)...)
+	res, err := r.exec(ctx, OpDelete, bldr.table, query, args...)
 	if err != nil {
 		return 0, err
 	}
@@ -807,18 +1076,23 @@ func (r *DB) Delete() (int64, error) {
 // Delete builds a DELETE stmt with corresponding where clause if stated
 // returning affected rows
 func (r *Txn) Delete() (int64, error) {
+	return r.DeleteCtx(context.Background())
+}
+
+// DeleteCtx builds a DELETE stmt with corresponding where clause if stated
+// returning affected rows with the given context
+func (r *Txn) DeleteCtx(ctx context.Context) (int64, error) {
 	if r.Tx == nil {
 		return 0, errTransactionModeWithoutTx
 	}
 
 	bldr := r.Builder
-	if bldr.table == "" {
-		return 0, errTableCallBeforeOp
+	query, args, err := bldr.deleteSQL()
+	if err != nil {
+		return 0, err
 	}
 
-	query := `DELETE FROM "` + r.Builder.table + `"`
-	query += r.Builder.buildClauses()
-	res, err := r.Tx.Exec(query, prepareValues(r.Builder.whereBindings)...)
+	res, err := r.exec(ctx, OpDelete, query, args...)
 	if err != nil {
 		return 0, err
 	}
@@ -828,23 +1102,23 @@ func (r *Txn) Delete() (int64, error) {
 
 // Replace inserts data if conflicting row hasn't been found, else it will update an existing one
 func (r *DB) Replace(data any, conflict string) (int64, error) {
+	return r.ReplaceCtx(context.Background(), data, conflict)
+}
+
+// ReplaceCtx inserts data if conflicting row hasn't been found, else it will update an existing one
+// with the given context
+func (r *DB) ReplaceCtx(ctx context.Context, data any, conflict string) (int64, error) {
 	if r.Txn != nil {
-		return r.Txn.Replace(data, conflict)
+		return r.txn().ReplaceCtx(ctx, data, conflict)
 	}
 
 	bldr := r.Builder
-	if bldr.table == "" {
-		return 0, errTableCallBeforeOp
-	}
-
-	columns, values, bindings := prepareBindingsForStruct(data)
-	query := `INSERT INTO "` + bldr.table + `" (` + strings.Join(columns, `, `) + `) VALUES(` + strings.Join(bindings, `, `) + `) ON CONFLICT(` + conflict + `) DO UPDATE SET `
-	for i, v := range columns {
-		columns[i] = v + " = excluded." + v
+	query, values, err := bldr.replaceSQL(data, conflict)
+	if err != nil {
+		return 0, err
 	}
 
-	query += strings.Join(columns, ", ")
-	res, err := r.Sql().Exec(query, values...)
+	res, err := r.exec(ctx, OpInsert, bldr.table, query, values...)
 	if err != nil {
 		return 0, err
 	}
@@ -854,23 +1128,23 @@ func (r *DB) Replace(data any, conflict string) (int64, error) {
 
 // Replace inserts data if conflicting row hasn't been found, else it will update an existing one
 func (r *Txn) Replace(data any, conflict string) (int64, error) {
+	return r.ReplaceCtx(context.Background(), data, conflict)
+}
+
+// ReplaceCtx inserts data if conflicting row hasn't been found, else it will update an existing one
+// with the given context
+func (r *Txn) ReplaceCtx(ctx context.Context, data any, conflict string) (int64, error) {
 	if r.Tx == nil {
 		return 0, errTransactionModeWithoutTx
 	}
 
 	bldr := r.Builder
-	if bldr.table == "" {
-		return 0, errTableCallBeforeOp
-	}
-
-	columns, values, bindings := prepareBindingsForStruct(data)
-	query := `INSERT INTO "` + bldr.table + `" (` + strings.Join(columns, `, `) + `) VALUES(` + strings.Join(bindings, `, `) + `) ON CONFLICT(` + conflict + `) DO UPDATE SET `
-	for i, v := range columns {
-		columns[i] = v + " = excluded." + v
+	query, values, err := bldr.replaceSQL(data, conflict)
+	if err != nil {
+		return 0, err
 	}
 
-	query += strings.Join(columns, ", ")
-	res, err := r.Tx.Exec(query, values...)
+	res, err := r.exec(ctx, OpInsert, query, values...)
 	if err != nil {
 		return 0, err
 	}
@@ -879,18 +1153,74 @@ func (r *Txn) Replace(data any, conflict string) (int64, error) {
 }
 
 // InTransaction executes fn passed as an argument in transaction mode
-// if there are no results returned - txn will be rolled back, otherwise committed and returned
+// if there are no results returned - txn will be rolled back, otherwise committed and returned,
+// being called in transaction mode it runs fn within a savepoint, so only the changes made by fn are rolled back,
+// db itself is switched into the transaction for fn, so use InTx or BeginTx to run transactions concurrently on shared db
 func (r *DB) InTransaction(fn func() (any, error)) error {
-	txn, err := r.Sql().Begin()
+	return r.InTransactionCtx(context.Background(), fn)
+}
+
+// InTransactionCtx executes fn passed as an argument in transaction mode started with the given context,
+// if the context is done before commit - the driver will roll back the transaction
+func (r *DB) InTransactionCtx(ctx context.Context, fn func() (any, error)) error {
+	return r.runTransaction(ctx, 1, nil, fn)
+}
+
+// InTransactionOpts executes fn in transaction mode as InTransactionCtx does starting transaction with options,
+// options are ignored in transaction mode as nested fn is run within a savepoint of the outer transaction
+func (r *DB) InTransactionOpts(ctx context.Context, opts *TxOptions, fn func() (any, error)) error {
+	return r.runTransaction(ctx, 1, opts, fn)
+}
+
+// runTransaction runs fn in transaction calling hooks around, attempt is reported to hooks
+func (r *DB) runTransaction(ctx context.Context, attempt int, opts *TxOptions, fn func() (any, error)) error {
+	if r.Txn != nil { // nested call
+		return r.runSavepoint(ctx, fn)
+	}
+
+	hooks := r.allHooks()
+	e := &TxEvent{Dialect: r.Builder.dialect.Name(), Attempt: attempt}
+	txCtx := beforeTx(ctx, hooks, e)
+
+	err := r.inTransaction(ctx, txCtx, hooks, e, opts, fn)
+	afterTx(txCtx, hooks, e, err)
+
+	return err
+}
+
+// committable reports whether result returned by fn passed to InTransaction means the transaction should be committed
+func committable(res any) bool {
+	switch v := res.(type) {
+	case int:
+		return v > 0
+	case int64:
+		return v > 0
+	case uint64:
+		return v > 0
+	case []map[string]any:
+		return len(v) > 0
+	case map[string]any:
+		return len(v) > 0
+	}
+
+	return false
+}
+
+// inTransaction runs fn in transaction marking event as committed on commit
+func (r *DB) inTransaction(ctx, txCtx context.Context, hooks []Hook, e *TxEvent, opts *TxOptions, fn func() (any, error)) error {
+	txn, err := r.beginTx(ctx, txCtx, hooks, opts)
 	if err != nil {
 		return err
 	}
 
 	// assign transaction + builder to Txn entity
-	r.Txn = &Txn{
+	tx := &Txn{
 		Tx:      txn,
 		Builder: r.Builder,
+		hooks:   hooks,
+		ctx:     txCtx,
 	}
+	r.Txn = tx
 
 	defer func() {
 		// clear Txn object after commit
@@ -898,45 +1228,23 @@ func (r *DB) InTransaction(fn func() (any, error)) error {
 	}()
 	res, err := fn()
 	if err != nil {
-		errTxn := txn.Rollback()
+		errTxn := tx.Rollback()
 		if errTxn != nil {
 			return errTxn
 		}
 		return err
 	}
 
-	isOk := false
-	switch v := res.(type) {
-	case int:
-		if v > 0 {
-			isOk = true
-		}
-	case int64:
-		if v > 0 {
-			isOk = true
-		}
-	case uint64:
-		if v > 0 {
-			isOk = true
-		}
-	case []map[string]any:
-		if len(v) > 0 {
-			isOk = true
-		}
-	case map[string]any:
-		if len(v) > 0 {
-			isOk = true
-		}
-	}
-
-	if !isOk {
-		return txn.Rollback()
+	if !committable(res) {
+		return tx.Rollback()
 	}
 
-	err = txn.Commit()
+	err = tx.Commit()
 	if err != nil {
 		return err
 	}
 
+	e.Committed = true
+	r.Conn.wrote(ctx)
 	return nil
 }
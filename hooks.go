@@ -0,0 +1,263 @@
+package buildsqlx
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Op is a kind of operation executed statement performs
+type Op string
+
+const (
+	OpSelect Op = "select"
+	OpInsert Op = "insert"
+	OpUpdate Op = "update"
+	OpDelete Op = "delete"
+	OpDDL    Op = "ddl"
+	// OpTx is a transaction control statement e.g.: SAVEPOINT
+	OpTx Op = "tx"
+)
+
+// QueryEvent describes statement sent to the driver, the same event is passed to BeforeQuery and AfterQuery,
+// Duration, RowsAffected and Err are set before AfterQuery is called
+type QueryEvent struct {
+	Op    Op
+	Table string
+	SQL   string
+	Args  []any
+	// Dialect is the name of dialect statement is built for e.g.: postgres
+	Dialect string
+	// InTx is true if statement is executed in transaction
+	InTx      bool
+	StartedAt time.Time
+	Duration  time.Duration
+	// RowsAffected is the number of rows affected by write or read by select statement
+	RowsAffected int64
+	Err          error
+}
+
+// Hook observes every statement sent to the driver,
+// context returned by BeforeQuery is used to execute the statement and is passed to AfterQuery
+type Hook interface {
+	BeforeQuery(ctx context.Context, e *QueryEvent) context.Context
+	AfterQuery(ctx context.Context, e *QueryEvent)
+}
+
+// TxEvent describes transaction started by InTransaction, Duration, Committed and Err are set before AfterTx is called
+type TxEvent struct {
+	Dialect string
+	// Attempt is 1-based number of transaction run, it's > 1 when transaction is retried by InTransactionRetry
+	Attempt   int
+	StartedAt time.Time
+	Duration  time.Duration
+	// Committed is false if transaction has been rolled back or failed to begin or commit
+	Committed bool
+	Err       error
+}
+
+// TxHook is optionally implemented by Hook to observe transactions started by InTransaction,
+// context returned by BeforeTx is the parent one for statements executed in transaction
+type TxHook interface {
+	BeforeTx(ctx context.Context, e *TxEvent) context.Context
+	AfterTx(ctx context.Context, e *TxEvent)
+}
+
+// HookFuncs adapts functions to Hook, any of them can be nil
+type HookFuncs struct {
+	Before func(ctx context.Context, e *QueryEvent) context.Context
+	After  func(ctx context.Context, e *QueryEvent)
+}
+
+// BeforeQuery calls Before func if set
+func (h HookFuncs) BeforeQuery(ctx context.Context, e *QueryEvent) context.Context {
+	if h.Before == nil {
+		return ctx
+	}
+
+	return h.Before(ctx, e)
+}
+
+// AfterQuery calls After func if set
+func (h HookFuncs) AfterQuery(ctx context.Context, e *QueryEvent) {
+	if h.After != nil {
+		h.After(ctx, e)
+	}
+}
+
+// WithHooks adds hooks called around every statement executed by DB
+func WithHooks(hooks ...Hook) Option {
+	return func(r *DB) {
+		r.AddHook(hooks...)
+	}
+}
+
+// AddHook adds hooks called around every statement executed by DB after the Connection hooks
+func (r *DB) AddHook(hooks ...Hook) *DB {
+	// copy on write, so queries started by Table before aren't affected
+	r.hooks = append(r.hooks[:len(r.hooks):len(r.hooks)], hooks...)
+	return r
+}
+
+// AddHook adds hooks called around every statement executed by any DB on this Connection
+func (c *Connection) AddHook(hooks ...Hook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// copy on write, so the slice got by DB isn't changed concurrently
+	c.hooks = append(c.hooks[:len(c.hooks):len(c.hooks)], hooks...)
+}
+
+// allHooks returns Connection hooks followed by DB hooks
+func (r *DB) allHooks() []Hook {
+	r.Conn.mu.RLock()
+	connHooks := r.Conn.hooks
+	r.Conn.mu.RUnlock()
+
+	if len(r.hooks) == 0 {
+		return connHooks
+	}
+
+	return append(connHooks[:len(connHooks):len(connHooks)], r.hooks...)
+}
+
+// event returns QueryEvent for statement built by builder
+func (r *builder) event(op Op, table, query string, args []any) *QueryEvent {
+	return &QueryEvent{Op: op, Table: table, SQL: query, Args: args, Dialect: r.dialect.Name()}
+}
+
+// hookCtx returns context for hooks, which carries values of transaction context in transaction mode
+func (r *DB) hookCtx(ctx context.Context) context.Context {
+	if r.Txn == nil {
+		return ctx
+	}
+
+	return r.Txn.hookCtx(ctx)
+}
+
+// hookCtx returns context for hooks, which carries values of context returned by TxHook.BeforeTx
+// e.g. the transaction span, while being cancelled by ctx passed to a statement
+func (r *Txn) hookCtx(ctx context.Context) context.Context {
+	if r.ctx == nil || r.ctx == ctx {
+		return ctx
+	}
+
+	return txContext{Context: ctx, tx: r.ctx}
+}
+
+// txContext looks up values in transaction context first
+type txContext struct {
+	context.Context
+	tx context.Context
+}
+
+// Value returns value from transaction context or statement context
+func (c txContext) Value(key any) any {
+	if v := c.tx.Value(key); v != nil {
+		return v
+	}
+
+	return c.Context.Value(key)
+}
+
+// beforeTx starts transaction event and calls TxHook hooks in order they were added
+func beforeTx(ctx context.Context, hooks []Hook, e *TxEvent) context.Context {
+	e.StartedAt = time.Now()
+	for _, h := range hooks {
+		if th, ok := h.(TxHook); ok {
+			ctx = th.BeforeTx(ctx, e)
+		}
+	}
+
+	return ctx
+}
+
+// afterTx finishes transaction event and calls TxHook hooks in reverse order
+func afterTx(ctx context.Context, hooks []Hook, e *TxEvent, err error) {
+	e.Duration = time.Since(e.StartedAt)
+	e.Err = err
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if th, ok := hooks[i].(TxHook); ok {
+			th.AfterTx(ctx, e)
+		}
+	}
+}
+
+// queryer runs statements on *sql.DB or *sql.Tx
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// beforeQuery starts the event and calls hooks in order they were added
+func beforeQuery(ctx context.Context, hooks []Hook, e *QueryEvent) context.Context {
+	e.StartedAt = time.Now()
+	for _, h := range hooks {
+		ctx = h.BeforeQuery(ctx, e)
+	}
+
+	return ctx
+}
+
+// afterQuery finishes the event and calls hooks in reverse order, so they are nested around the statement
+func afterQuery(ctx context.Context, hooks []Hook, e *QueryEvent, err error) {
+	e.Duration = time.Since(e.StartedAt)
+	e.Err = err
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i].AfterQuery(ctx, e)
+	}
+}
+
+// execHooked executes statement calling hooks around it
+func execHooked(ctx context.Context, q queryer, hooks []Hook, e *QueryEvent) (sql.Result, error) {
+	ctx = beforeQuery(ctx, hooks, e)
+	res, err := q.ExecContext(ctx, e.SQL, e.Args...)
+	if err == nil {
+		// not every driver or stmt reports affected rows, so an error is ignored
+		e.RowsAffected, _ = res.RowsAffected()
+	}
+	afterQuery(ctx, hooks, e, err)
+
+	return res, err
+}
+
+// queryRowHooked queries a single row scanning it into dest and calling hooks around
+func queryRowHooked(ctx context.Context, q queryer, hooks []Hook, e *QueryEvent, dest ...any) error {
+	ctx = beforeQuery(ctx, hooks, e)
+	err := q.QueryRowContext(ctx, e.SQL, e.Args...).Scan(dest...)
+	if err == nil {
+		e.RowsAffected = 1
+	}
+	afterQuery(ctx, hooks, e, err)
+
+	return err
+}
+
+// queryHooked queries rows calling hooks before, the returned done func must be called after rows are read
+// with the number of rows and an error occurred while reading to call hooks after and close rows
+func queryHooked(ctx context.Context, q queryer, hooks []Hook, e *QueryEvent) (*sql.Rows, func(n int64, err error) error, error) {
+	ctx = beforeQuery(ctx, hooks, e)
+	rows, err := q.QueryContext(ctx, e.SQL, e.Args...)
+	if err != nil {
+		afterQuery(ctx, hooks, e, err)
+		return nil, nil, err
+	}
+
+	done := func(n int64, err error) error {
+		if err == nil {
+			err = rows.Err()
+		}
+
+		if errClose := rows.Close(); err == nil {
+			err = errClose
+		}
+
+		e.RowsAffected = n
+		afterQuery(ctx, hooks, e, err)
+
+		return err
+	}
+
+	return rows, done, nil
+}
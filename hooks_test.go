@@ -0,0 +1,99 @@
+package buildsqlx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type ctxKey struct{}
+
+// recordingHook collects finished events
+type recordingHook struct {
+	events []QueryEvent
+}
+
+func (h *recordingHook) BeforeQuery(ctx context.Context, e *QueryEvent) context.Context {
+	return context.WithValue(ctx, ctxKey{}, e.SQL)
+}
+
+func (h *recordingHook) AfterQuery(ctx context.Context, e *QueryEvent) {
+	if ctx.Value(ctxKey{}) == e.SQL {
+		h.events = append(h.events, *e)
+	}
+}
+
+func (h *recordingHook) ops() []Op {
+	ops := make([]Op, len(h.events))
+	for i, e := range h.events {
+		ops[i] = e.Op
+	}
+
+	return ops
+}
+
+func TestDB_Hooks(t *testing.T) {
+	connHook, dbHook := &recordingHook{}, &recordingHook{}
+	conn := NewConnection("postgres", dbConnInfo)
+	conn.AddHook(connHook)
+	hdb := NewDb(conn, WithHooks(dbHook))
+
+	_, err := hdb.Truncate(TestTable)
+	require.NoError(t, err)
+
+	err = hdb.Table(TestTable).Insert(data)
+	require.NoError(t, err)
+
+	var ds DataStruct
+	err = hdb.Table(TestTable).Select("foo", "bar").Where("foo", "=", data.Foo).ScanStruct(&ds)
+	require.NoError(t, err)
+
+	_, err = hdb.Table(TestTable).Where("foo", "=", data.Foo).Update(DataStruct{Foo: "foo"})
+	require.NoError(t, err)
+
+	_, err = hdb.Table("no_such_table").Delete()
+	require.Error(t, err)
+
+	err = hdb.InTransaction(func() (any, error) {
+		return hdb.Table(TestTable).Where("foo", "=", "foo").Delete()
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []Op{OpDDL, OpInsert, OpSelect, OpUpdate, OpDelete, OpDelete}, connHook.ops())
+	require.Equal(t, connHook.events, dbHook.events)
+
+	sel := connHook.events[2]
+	require.Equal(t, TestTable, sel.Table)
+	require.Equal(t, `SELECT foo, bar FROM "test" WHERE foo = $1 LIMIT 1`, sel.SQL)
+	require.Equal(t, []any{data.Foo}, sel.Args)
+	require.Equal(t, int64(1), sel.RowsAffected)
+	require.True(t, sel.Duration > 0)
+
+	require.Equal(t, int64(1), connHook.events[3].RowsAffected)
+	require.Error(t, connHook.events[4].Err)
+	require.False(t, connHook.events[4].InTx)
+	require.True(t, connHook.events[5].InTx)
+	require.Equal(t, int64(1), connHook.events[5].RowsAffected)
+}
+
+func TestHookFuncs(t *testing.T) {
+	var order []string
+	hooks := []Hook{
+		HookFuncs{Before: func(ctx context.Context, e *QueryEvent) context.Context {
+			order = append(order, "before 1")
+			return ctx
+		}, After: func(ctx context.Context, e *QueryEvent) {
+			order = append(order, "after 1")
+		}},
+		HookFuncs{After: func(ctx context.Context, e *QueryEvent) {
+			order = append(order, "after 2")
+		}},
+	}
+
+	e := &QueryEvent{Op: OpSelect}
+	ctx := beforeQuery(context.Background(), hooks, e)
+	afterQuery(ctx, hooks, e, nil)
+	require.Equal(t, []string{"before 1", "after 2", "after 1"}, order)
+	require.False(t, e.StartedAt.IsZero())
+}
@@ -0,0 +1,186 @@
+package buildsqlx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Logger is a Hook emitting log/slog record per statement,
+// statements slower than threshold are logged with slow level and failed ones with error level
+type Logger struct {
+	logger    *slog.Logger
+	level     slog.Level
+	slowLevel slog.Level
+	slow      time.Duration
+	redact    func(args []any) []any
+	sampling  map[string]uint64
+	counters  sync.Map // table -> *uint64
+}
+
+// LoggerOption configures Logger
+type LoggerOption func(*Logger)
+
+// WithLogLevel sets the level statements are logged with, slog.LevelDebug is used by default
+func WithLogLevel(level slog.Level) LoggerOption {
+	return func(l *Logger) {
+		l.level = level
+	}
+}
+
+// WithSlowThreshold escalates the level of statements running longer than threshold to slow level
+func WithSlowThreshold(threshold time.Duration, level slog.Level) LoggerOption {
+	return func(l *Logger) {
+		l.slow = threshold
+		l.slowLevel = level
+	}
+}
+
+// WithSampling logs only every n-th statement for the table, slow and failed statements are always logged
+func WithSampling(table string, n uint64) LoggerOption {
+	return func(l *Logger) {
+		if n > 1 {
+			l.sampling[table] = n
+		}
+	}
+}
+
+// WithArgsRedactor sets the func args are passed through before logging,
+// RedactArgs is used by default, pass nil to log args as is
+func WithArgsRedactor(fn func(args []any) []any) LoggerOption {
+	return func(l *Logger) {
+		l.redact = fn
+	}
+}
+
+// RedactArgs replaces values with their types, so no personal data or secrets get into logs
+func RedactArgs(args []any) []any {
+	redacted := make([]any, len(args))
+	for i, arg := range args {
+		if arg != nil {
+			redacted[i] = fmt.Sprintf("%T", arg)
+		}
+	}
+
+	return redacted
+}
+
+// NewLogger returns Logger hook writing to logger, slog.Default() is used if logger is nil
+func NewLogger(logger *slog.Logger, opts ...LoggerOption) *Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	l := &Logger{
+		logger:    logger,
+		level:     slog.LevelDebug,
+		slowLevel: slog.LevelWarn,
+		redact:    RedactArgs,
+		sampling:  make(map[string]uint64),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// WithLogger adds Logger hook to DB
+func WithLogger(logger *slog.Logger, opts ...LoggerOption) Option {
+	return WithHooks(NewLogger(logger, opts...))
+}
+
+// BeforeQuery does nothing as statement is logged when it's done
+func (l *Logger) BeforeQuery(ctx context.Context, _ *QueryEvent) context.Context {
+	return ctx
+}
+
+// AfterQuery logs the finished statement
+func (l *Logger) AfterQuery(ctx context.Context, e *QueryEvent) {
+	level, slow := l.level, l.slow > 0 && e.Duration >= l.slow
+	if slow {
+		level = l.slowLevel
+	}
+
+	if e.Err != nil {
+		level = slog.LevelError
+	} else if !slow && !l.sampled(e.Table) {
+		return
+	}
+
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	args := e.Args
+	if l.redact != nil {
+		args = l.redact(args)
+	}
+
+	attrs := []slog.Attr{
+		slog.String("op", string(e.Op)),
+		slog.String("table", e.Table),
+		slog.String("sql", e.SQL),
+		slog.Any("args", args),
+		slog.Duration("duration", e.Duration),
+		slog.Int64("rows", e.RowsAffected),
+	}
+
+	if e.InTx {
+		attrs = append(attrs, slog.Bool("in_tx", true))
+	}
+
+	if slow {
+		attrs = append(attrs, slog.Bool("slow", true))
+	}
+
+	if e.Err != nil {
+		attrs = append(attrs, slog.String("error", e.Err.Error()))
+	}
+
+	l.logger.LogAttrs(ctx, level, "sql query", attrs...)
+}
+
+// BeforeTx does nothing as transaction is logged when it's done
+func (l *Logger) BeforeTx(ctx context.Context, _ *TxEvent) context.Context {
+	return ctx
+}
+
+// AfterTx logs the finished transaction, failed attempts are logged with error level
+func (l *Logger) AfterTx(ctx context.Context, e *TxEvent) {
+	level := l.level
+	if e.Err != nil {
+		level = slog.LevelError
+	}
+
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.Bool("committed", e.Committed),
+		slog.Int("attempt", e.Attempt),
+		slog.Duration("duration", e.Duration),
+	}
+
+	if e.Err != nil {
+		attrs = append(attrs, slog.String("error", e.Err.Error()))
+	}
+
+	l.logger.LogAttrs(ctx, level, "sql transaction", attrs...)
+}
+
+// sampled reports whether the statement for table should be logged according to sampling rate
+func (l *Logger) sampled(table string) bool {
+	n, ok := l.sampling[table]
+	if !ok {
+		return true
+	}
+
+	cnt, _ := l.counters.LoadOrStore(table, new(uint64))
+
+	return (atomic.AddUint64(cnt.(*uint64), 1)-1)%n == 0
+}
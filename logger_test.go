@@ -0,0 +1,85 @@
+package buildsqlx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		rec := map[string]any{}
+		require.NoError(t, json.Unmarshal([]byte(line), &rec))
+		records = append(records, rec)
+	}
+
+	return records
+}
+
+func TestLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		WithSlowThreshold(100*time.Millisecond, slog.LevelWarn))
+
+	ctx := context.Background()
+	l.AfterQuery(ctx, &QueryEvent{Op: OpSelect, Table: UsersTable, SQL: "SELECT * FROM test_users WHERE name = $1",
+		Args: []any{"Alex", nil}, Duration: time.Millisecond, RowsAffected: 3})
+	l.AfterQuery(ctx, &QueryEvent{Op: OpUpdate, Table: UsersTable, SQL: "UPDATE test_users SET points = 1",
+		Duration: time.Second, RowsAffected: 10, InTx: true})
+	l.AfterQuery(ctx, &QueryEvent{Op: OpDelete, Table: UsersTable, SQL: "DELETE FROM test_users", Err: errors.New("some err")})
+
+	records := logRecords(t, buf)
+	require.Len(t, records, 3)
+
+	require.Equal(t, "DEBUG", records[0]["level"])
+	require.Equal(t, "sql query", records[0]["msg"])
+	require.Equal(t, "select", records[0]["op"])
+	require.Equal(t, UsersTable, records[0]["table"])
+	require.Equal(t, []any{"string", nil}, records[0]["args"])
+	require.Equal(t, float64(3), records[0]["rows"])
+
+	require.Equal(t, "WARN", records[1]["level"])
+	require.Equal(t, true, records[1]["slow"])
+	require.Equal(t, true, records[1]["in_tx"])
+
+	require.Equal(t, "ERROR", records[2]["level"])
+	require.Equal(t, "some err", records[2]["error"])
+}
+
+func TestLogger_Sampling(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo})),
+		WithLogLevel(slog.LevelInfo), WithSampling(PostsTable, 3), WithArgsRedactor(nil))
+
+	ctx := context.Background()
+	for i := 0; i < 7; i++ {
+		l.AfterQuery(ctx, &QueryEvent{Op: OpInsert, Table: PostsTable, Args: []any{i}})
+	}
+	l.AfterQuery(ctx, &QueryEvent{Op: OpInsert, Table: PostsTable, Err: errors.New("some err")})
+	l.AfterQuery(ctx, &QueryEvent{Op: OpInsert, Table: UsersTable})
+
+	records := logRecords(t, buf)
+	require.Len(t, records, 5)
+	require.Equal(t, []any{float64(0)}, records[0]["args"])
+	require.Equal(t, []any{float64(3)}, records[1]["args"])
+	require.Equal(t, []any{float64(6)}, records[2]["args"])
+	require.Equal(t, "ERROR", records[3]["level"])
+	require.Equal(t, UsersTable, records[4]["table"])
+
+	// below the handler level nothing is logged
+	buf.Reset()
+	NewLogger(slog.New(slog.NewJSONHandler(buf, nil))).AfterQuery(ctx, &QueryEvent{Op: OpSelect})
+	require.Empty(t, buf.String())
+}
@@ -0,0 +1,174 @@
+package buildsqlx
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultConnection is the name of connection returned by Manager.Default
+// unless another one is set with Manager.SetDefault
+const DefaultConnection = "default"
+
+// Manager is a registry of named connections to several databases
+type Manager struct {
+	mu          sync.RWMutex
+	conns       map[string]*managedConn
+	defaultName string
+}
+
+// managedConn keeps connection with options applied to every DB built from it
+type managedConn struct {
+	conn *Connection
+	opts []Option
+}
+
+// NewManager returns an empty connection registry
+func NewManager() *Manager {
+	return &Manager{conns: make(map[string]*managedConn), defaultName: DefaultConnection}
+}
+
+// Register adds connection by name, options e.g.: WithDialect are applied to every DB returned for this name
+func (m *Manager) Register(name string, conn *Connection, opts ...Option) error {
+	if conn == nil {
+		return fmt.Errorf("sql: connection '%s' is nil", name)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.conns[name]; ok {
+		return fmt.Errorf("sql: connection '%s' is already registered", name)
+	}
+
+	m.conns[name] = &managedConn{conn: conn, opts: opts}
+
+	return nil
+}
+
+// SetDefault sets the registered connection name to be returned by Default
+func (m *Manager) SetDefault(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.conns[name]; !ok {
+		return fmt.Errorf("sql: connection '%s' is not registered", name)
+	}
+
+	m.defaultName = name
+
+	return nil
+}
+
+// Get returns new DB bound to the named connection or an error if there is no such connection
+func (m *Manager) Get(name string) (*DB, error) {
+	m.mu.RLock()
+	mc, ok := m.conns[name]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("sql: connection '%s' is not registered", name)
+	}
+
+	return NewDb(mc.conn, mc.opts...), nil
+}
+
+// DB returns new DB bound to the named connection, it panics if there is no such connection,
+// a new DB is returned on every call, so it can be used by a single goroutine as any other DB
+func (m *Manager) DB(name string) *DB {
+	db, err := m.Get(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return db
+}
+
+// Default returns new DB bound to the default connection
+func (m *Manager) Default() *DB {
+	m.mu.RLock()
+	name := m.defaultName
+	m.mu.RUnlock()
+
+	return m.DB(name)
+}
+
+// Connection returns the named connection e.g.: to get its Stats
+func (m *Manager) Connection(name string) (*Connection, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	mc, ok := m.conns[name]
+	if !ok {
+		return nil, false
+	}
+
+	return mc.conn, true
+}
+
+// Names returns sorted names of registered connections
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.conns))
+	for name := range m.conns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Health pings every registered connection and returns errors by connection name, an empty map means all are alive
+func (m *Manager) Health(ctx context.Context) map[string]error {
+	errs := make(map[string]error)
+	for _, name := range m.Names() {
+		conn, ok := m.Connection(name)
+		if !ok {
+			continue
+		}
+
+		if err := conn.Ping(ctx); err != nil {
+			errs[name] = err
+		}
+	}
+
+	return errs
+}
+
+// Ping pings every registered connection, returning the first error in connection name order
+func (m *Manager) Ping(ctx context.Context) error {
+	errs := m.Health(ctx)
+	for _, name := range m.Names() {
+		if err, ok := errs[name]; ok {
+			return fmt.Errorf("sql: connection '%s': %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Close closes all registered connections and removes them from registry,
+// the first error is returned while the rest of connections are closed anyway
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	conns := m.conns
+	m.conns = make(map[string]*managedConn)
+	m.mu.Unlock()
+
+	names := make([]string, 0, len(conns))
+	for name := range conns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var err error
+	for _, name := range names {
+		if errClose := conns[name].conn.Close(); errClose != nil && err == nil {
+			err = fmt.Errorf("sql: connection '%s': %w", name, errClose)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,63 @@
+package buildsqlx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager(t *testing.T) {
+	m := NewManager()
+	main, billing := &Connection{}, &Connection{}
+	require.NoError(t, m.Register(DefaultConnection, main))
+	require.NoError(t, m.Register("billing", billing, WithDialect(MySQL{})))
+	require.EqualError(t, m.Register("billing", billing), "sql: connection 'billing' is already registered")
+	require.EqualError(t, m.Register("nil", nil), "sql: connection 'nil' is nil")
+	require.Equal(t, []string{"billing", DefaultConnection}, m.Names())
+
+	require.Same(t, main, m.Default().Conn)
+	require.Equal(t, "postgres", m.Default().Dialect().Name())
+	require.Same(t, billing, m.DB("billing").Conn)
+	require.Equal(t, "mysql", m.DB("billing").Dialect().Name())
+	// every call returns independent DB
+	require.False(t, m.DB("billing") == m.DB("billing"))
+
+	_, err := m.Get("analytics")
+	require.EqualError(t, err, "sql: connection 'analytics' is not registered")
+	require.Panics(t, func() {
+		m.DB("analytics")
+	})
+
+	require.EqualError(t, m.SetDefault("analytics"), "sql: connection 'analytics' is not registered")
+	require.NoError(t, m.SetDefault("billing"))
+	require.Same(t, billing, m.Default().Conn)
+
+	conn, ok := m.Connection("billing")
+	require.True(t, ok)
+	require.Same(t, billing, conn)
+}
+
+func TestManager_HealthAndClose(t *testing.T) {
+	alive, err := Open("postgres", dbConnInfo)
+	require.NoError(t, err)
+	down, err := Open("postgres", "host=localhost port=1 user=postgres sslmode=disable")
+	require.NoError(t, err)
+
+	m := NewManager()
+	require.NoError(t, m.Register(DefaultConnection, alive))
+	require.NoError(t, m.Register("analytics", down))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	errs := m.Health(ctx)
+	require.Len(t, errs, 1)
+	require.Error(t, errs["analytics"])
+	require.Contains(t, m.Ping(ctx).Error(), "sql: connection 'analytics': ")
+
+	require.NoError(t, m.Close())
+	require.Empty(t, m.Names())
+	require.Error(t, alive.Ping(ctx))
+}
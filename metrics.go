@@ -0,0 +1,310 @@
+package buildsqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// QueryMetric is a statement observation passed to Collector
+type QueryMetric struct {
+	Op       Op
+	Table    string
+	Duration time.Duration
+	// ErrClass is SQLSTATE class of an error e.g.: 23 for integrity constraint violation, empty if there is no error
+	ErrClass string
+}
+
+// Collector receives observations from Metrics hook, implement it to send metrics to a backend of choice
+type Collector interface {
+	ObserveQuery(m QueryMetric)
+	ObserveTx(committed bool)
+}
+
+// Metrics is a Hook passing statements and transactions started by InTransaction to Collector
+type Metrics struct {
+	collector Collector
+}
+
+// NewMetrics returns Metrics hook observing into collector
+func NewMetrics(collector Collector) *Metrics {
+	return &Metrics{collector: collector}
+}
+
+// WithMetrics adds Metrics hook to DB
+func WithMetrics(collector Collector) Option {
+	return WithHooks(NewMetrics(collector))
+}
+
+// BeforeQuery does nothing as statement is observed when it's done
+func (m *Metrics) BeforeQuery(ctx context.Context, _ *QueryEvent) context.Context {
+	return ctx
+}
+
+// AfterQuery observes the finished statement
+func (m *Metrics) AfterQuery(_ context.Context, e *QueryEvent) {
+	m.collector.ObserveQuery(QueryMetric{Op: e.Op, Table: e.Table, Duration: e.Duration, ErrClass: errClass(e.Err)})
+}
+
+// BeforeTx does nothing as transaction is observed when it's done
+func (m *Metrics) BeforeTx(ctx context.Context, _ *TxEvent) context.Context {
+	return ctx
+}
+
+// AfterTx observes commit or rollback
+func (m *Metrics) AfterTx(_ context.Context, e *TxEvent) {
+	m.collector.ObserveTx(e.Committed)
+}
+
+// errClass returns SQLSTATE class of err, no rows found is reported as 02 - no data,
+// errors without SQLSTATE are reported as unknown
+func errClass(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return "02"
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code.Class())
+	}
+
+	// e.g.: pgx *pgconn.PgError
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) && len(stateErr.SQLState()) >= 2 {
+		return stateErr.SQLState()[:2]
+	}
+
+	return "unknown"
+}
+
+// DefaultBuckets are query latency histogram buckets in seconds
+var DefaultBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// PrometheusCollector is an in-memory Collector exposing metrics in Prometheus text format
+type PrometheusCollector struct {
+	mu        sync.Mutex
+	buckets   []float64
+	queries   map[queryKey]*histogram
+	errors    map[errKey]uint64
+	commits   uint64
+	rollbacks uint64
+	pools     map[string]*Connection
+}
+
+type queryKey struct {
+	op    Op
+	table string
+}
+
+func (k queryKey) less(other queryKey) bool {
+	if k.op != other.op {
+		return k.op < other.op
+	}
+
+	return k.table < other.table
+}
+
+type errKey struct {
+	queryKey
+	class string
+}
+
+// histogram keeps cumulative bucket counters
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewPrometheusCollector returns PrometheusCollector with latency histogram buckets in seconds,
+// DefaultBuckets are used if there are no buckets passed
+func NewPrometheusCollector(buckets ...float64) *PrometheusCollector {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &PrometheusCollector{
+		buckets: buckets,
+		queries: make(map[queryKey]*histogram),
+		errors:  make(map[errKey]uint64),
+		pools:   make(map[string]*Connection),
+	}
+}
+
+// ObserveQuery counts statement, its latency and error class if any
+func (c *PrometheusCollector) ObserveQuery(m QueryMetric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := queryKey{op: m.Op, table: m.Table}
+	h, ok := c.queries[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(c.buckets))}
+		c.queries[key] = h
+	}
+
+	seconds := m.Duration.Seconds()
+	for i, le := range c.buckets {
+		if seconds <= le {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+
+	if m.ErrClass != "" {
+		c.errors[errKey{queryKey: key, class: m.ErrClass}]++
+	}
+}
+
+// ObserveTx counts transaction commit or rollback
+func (c *PrometheusCollector) ObserveTx(committed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if committed {
+		c.commits++
+	} else {
+		c.rollbacks++
+	}
+}
+
+// AddPool exposes connection pool statistics of conn as gauges labeled by name
+func (c *PrometheusCollector) AddPool(name string, conn *Connection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pools[name] = conn
+}
+
+// WriteTo writes metrics in Prometheus text exposition format
+func (c *PrometheusCollector) WriteTo(w io.Writer) (int64, error) {
+	sb := &strings.Builder{}
+	c.mu.Lock()
+
+	queryKeys := make([]queryKey, 0, len(c.queries))
+	for key := range c.queries {
+		queryKeys = append(queryKeys, key)
+	}
+	sort.Slice(queryKeys, func(i, j int) bool {
+		return queryKeys[i].less(queryKeys[j])
+	})
+
+	writeHeader(sb, "buildsqlx_queries_total", "counter", "Number of executed statements.")
+	for _, key := range queryKeys {
+		writeSample(sb, "buildsqlx_queries_total", labels(key), float64(c.queries[key].count))
+	}
+
+	errKeys := make([]errKey, 0, len(c.errors))
+	for key := range c.errors {
+		errKeys = append(errKeys, key)
+	}
+	sort.Slice(errKeys, func(i, j int) bool {
+		if errKeys[i].queryKey != errKeys[j].queryKey {
+			return errKeys[i].queryKey.less(errKeys[j].queryKey)
+		}
+
+		return errKeys[i].class < errKeys[j].class
+	})
+
+	writeHeader(sb, "buildsqlx_query_errors_total", "counter", "Number of failed statements by SQLSTATE class.")
+	for _, key := range errKeys {
+		writeSample(sb, "buildsqlx_query_errors_total", labels(key.queryKey)+`,class="`+escapeLabel(key.class)+`"`, float64(c.errors[key]))
+	}
+
+	writeHeader(sb, "buildsqlx_query_duration_seconds", "histogram", "Statement latency.")
+	for _, key := range queryKeys {
+		h, lbls := c.queries[key], labels(key)
+		for i, le := range c.buckets {
+			writeSample(sb, "buildsqlx_query_duration_seconds_bucket", lbls+`,le="`+formatFloat(le)+`"`, float64(h.counts[i]))
+		}
+		writeSample(sb, "buildsqlx_query_duration_seconds_bucket", lbls+`,le="+Inf"`, float64(h.count))
+		writeSample(sb, "buildsqlx_query_duration_seconds_sum", lbls, h.sum)
+		writeSample(sb, "buildsqlx_query_duration_seconds_count", lbls, float64(h.count))
+	}
+
+	writeHeader(sb, "buildsqlx_transactions_total", "counter", "Number of transactions run by InTransaction by result.")
+	writeSample(sb, "buildsqlx_transactions_total", `result="commit"`, float64(c.commits))
+	writeSample(sb, "buildsqlx_transactions_total", `result="rollback"`, float64(c.rollbacks))
+
+	names := make([]string, 0, len(c.pools))
+	for name := range c.pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	stats := make([]sql.DBStats, len(names))
+	for i, name := range names {
+		stats[i] = c.pools[name].Stats()
+	}
+	c.mu.Unlock()
+
+	if len(names) > 0 {
+		poolGauges := []struct {
+			name, typ, help string
+			value           func(s sql.DBStats) float64
+		}{
+			{"buildsqlx_pool_max_open_connections", "gauge", "Maximum number of open connections.", func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }},
+			{"buildsqlx_pool_open_connections", "gauge", "Number of established connections.", func(s sql.DBStats) float64 { return float64(s.OpenConnections) }},
+			{"buildsqlx_pool_in_use_connections", "gauge", "Number of connections in use.", func(s sql.DBStats) float64 { return float64(s.InUse) }},
+			{"buildsqlx_pool_idle_connections", "gauge", "Number of idle connections.", func(s sql.DBStats) float64 { return float64(s.Idle) }},
+			{"buildsqlx_pool_wait_count_total", "counter", "Number of connections waited for.", func(s sql.DBStats) float64 { return float64(s.WaitCount) }},
+			{"buildsqlx_pool_wait_duration_seconds_total", "counter", "Time blocked waiting for a new connection.", func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }},
+		}
+
+		for _, g := range poolGauges {
+			writeHeader(sb, g.name, g.typ, g.help)
+			for i, name := range names {
+				writeSample(sb, g.name, `pool="`+escapeLabel(name)+`"`, g.value(stats[i]))
+			}
+		}
+	}
+
+	n, err := io.WriteString(w, sb.String())
+
+	return int64(n), err
+}
+
+// ServeHTTP serves metrics to Prometheus scraper
+func (c *PrometheusCollector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = c.WriteTo(w)
+}
+
+func writeHeader(sb *strings.Builder, name, typ, help string) {
+	fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func writeSample(sb *strings.Builder, name, labels string, value float64) {
+	fmt.Fprintf(sb, "%s{%s} %s\n", name, labels, formatFloat(value))
+}
+
+func labels(key queryKey) string {
+	return `op="` + escapeLabel(string(key.op)) + `",table="` + escapeLabel(key.table) + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// escapeLabel escapes label value according to text exposition format
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
@@ -0,0 +1,69 @@
+package buildsqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusCollector(t *testing.T) {
+	c := NewPrometheusCollector(0.01, 0.1)
+	m := NewMetrics(c)
+	ctx := context.Background()
+
+	m.AfterQuery(ctx, &QueryEvent{Op: OpSelect, Table: UsersTable, Duration: 5 * time.Millisecond})
+	m.AfterQuery(ctx, &QueryEvent{Op: OpSelect, Table: UsersTable, Duration: 50 * time.Millisecond, Err: sql.ErrNoRows})
+	m.AfterQuery(ctx, &QueryEvent{Op: OpInsert, Table: `tbl"1`, Duration: time.Second,
+		Err: fmt.Errorf("insert: %w", &pq.Error{Code: "23505"})})
+	m.AfterTx(ctx, &TxEvent{Committed: true})
+	m.AfterTx(ctx, &TxEvent{})
+	m.AfterTx(ctx, &TxEvent{})
+	c.AddPool("main", NewConnectionFromDb(&sql.DB{}))
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	out := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE buildsqlx_queries_total counter",
+		`buildsqlx_queries_total{op="insert",table="tbl\"1"} 1`,
+		`buildsqlx_queries_total{op="select",table="test_users"} 2`,
+		`buildsqlx_query_errors_total{op="insert",table="tbl\"1",class="23"} 1`,
+		`buildsqlx_query_errors_total{op="select",table="test_users",class="02"} 1`,
+		"# TYPE buildsqlx_query_duration_seconds histogram",
+		`buildsqlx_query_duration_seconds_bucket{op="select",table="test_users",le="0.01"} 1`,
+		`buildsqlx_query_duration_seconds_bucket{op="select",table="test_users",le="0.1"} 2`,
+		`buildsqlx_query_duration_seconds_bucket{op="select",table="test_users",le="+Inf"} 2`,
+		`buildsqlx_query_duration_seconds_bucket{op="insert",table="tbl\"1",le="0.1"} 0`,
+		`buildsqlx_query_duration_seconds_sum{op="insert",table="tbl\"1"} 1`,
+		`buildsqlx_query_duration_seconds_count{op="select",table="test_users"} 2`,
+		`buildsqlx_transactions_total{result="commit"} 1`,
+		`buildsqlx_transactions_total{result="rollback"} 2`,
+		`buildsqlx_pool_open_connections{pool="main"} 0`,
+		`buildsqlx_pool_wait_count_total{pool="main"} 0`,
+	} {
+		require.Contains(t, out, line+"\n")
+	}
+
+	// output is stable between scrapes
+	sb := &strings.Builder{}
+	_, err := c.WriteTo(sb)
+	require.NoError(t, err)
+	require.Equal(t, out, sb.String())
+}
+
+func TestErrClass(t *testing.T) {
+	require.Empty(t, errClass(nil))
+	require.Equal(t, "02", errClass(sql.ErrNoRows))
+	require.Equal(t, "40", errClass(&pq.Error{Code: "40001"}))
+	require.Equal(t, "unknown", errClass(errors.New("some err")))
+}
@@ -0,0 +1,195 @@
+package buildsqlx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rawExpr is a custom SQL fragment applied as is with its own args bound to ? or $n placeholders,
+// which are renumbered on building the query, so the fragment can be mixed with any other clauses
+type rawExpr struct {
+	parts []string // fragment split by placeholders, there is one more part than refs
+	refs  []int    // indices of args bound to placeholders in order of their appearance
+	args  []any
+	// err is returned on building the query if placeholders don't match args
+	err error
+}
+
+// newRawExpr parses placeholders of fragment, ? is bound to the next arg and $n to the n-th one,
+// ?? is unescaped to ? e.g. of jsonb operator, placeholders inside quoted literals and of fragment without args are left as is,
+// err is set if placeholders don't match args or some arg is an empty slice
+func newRawExpr(sql string, args []any) *rawExpr {
+	e := &rawExpr{args: args}
+	bind := len(args) > 0
+	used := make([]bool, len(args))
+
+	var part strings.Builder
+	quoted := false
+	next := 0
+	for k := 0; k < len(sql); k++ {
+		c := sql[k]
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '?' && k+1 < len(sql) && sql[k+1] == '?':
+			k++
+		case c == '?' && bind:
+			if next >= len(args) {
+				e.err = fmt.Errorf("sql: raw expression '%s' has more placeholders than %d args", sql, len(args))
+				return e
+			}
+
+			e.parts = append(e.parts, part.String())
+			e.refs = append(e.refs, next)
+			used[next] = true
+			part.Reset()
+			next++
+			continue
+		case c == '$' && bind && k+1 < len(sql) && sql[k+1] >= '0' && sql[k+1] <= '9':
+			end := k + 1
+			n := 0
+			for end < len(sql) && sql[end] >= '0' && sql[end] <= '9' {
+				n = n*10 + int(sql[end]-'0')
+				end++
+			}
+
+			if n < 1 || n > len(args) {
+				e.err = fmt.Errorf("sql: raw expression '%s' refers to $%d out of %d args", sql, n, len(args))
+				return e
+			}
+
+			e.parts = append(e.parts, part.String())
+			e.refs = append(e.refs, n-1)
+			used[n-1] = true
+			part.Reset()
+			k = end - 1
+			continue
+		}
+
+		part.WriteByte(c)
+	}
+	e.parts = append(e.parts, part.String())
+
+	for n, arg := range args {
+		if !used[n] {
+			e.err = fmt.Errorf("sql: raw expression '%s' has no placeholder for arg %d", sql, n+1)
+			return e
+		}
+
+		if len(prepareValue(arg)) == 0 {
+			e.err = fmt.Errorf("sql: raw expression '%s' binds empty slice to placeholder of arg %d", sql, n+1)
+			return e
+		}
+	}
+
+	return e
+}
+
+// compile constructs fragment numbering placeholders from i, []any arg is expanded to the list of placeholders
+func (e *rawExpr) compile(d Dialect, i *int) string {
+	sql := e.parts[0]
+	for k, ref := range e.refs {
+		n := len(prepareValue(e.args[ref]))
+		placeholders := make([]string, 0, n)
+		for j := 0; j < n; j++ {
+			placeholders = append(placeholders, d.Placeholder(*i))
+			*i++
+		}
+
+		sql += strings.Join(placeholders, ", ") + e.parts[k+1]
+	}
+
+	return sql
+}
+
+// bound returns values bound to fragment in order of their placeholders
+func (e *rawExpr) bound() []any {
+	var args []any
+	for _, ref := range e.refs {
+		args = append(args, prepareValue(e.args[ref])...)
+	}
+
+	return args
+}
+
+// validate returns the first error of raw expressions in the query, its nested queries and common table expressions
+func (r *builder) validate() error {
+	for _, c := range r.ctes {
+		if err := c.b.validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, part := range r.union {
+		if err := part.validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range []*rawExpr{r.raw, r.selectRaw, r.orderByRaw} {
+		if e != nil && e.err != nil {
+			return e.err
+		}
+	}
+
+	for _, s := range r.selectSubs {
+		if err := s.b.validate(); err != nil {
+			return err
+		}
+	}
+
+	if r.fromSub != nil {
+		if err := r.fromSub.b.validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, j := range r.join {
+		if err := validateConditions(j.on); err != nil {
+			return err
+		}
+	}
+
+	if err := validateConditions(r.whereBindings); err != nil {
+		return err
+	}
+
+	return validateConditions(r.havingBindings)
+}
+
+// validateConditions returns the first error of raw expressions in conditions including nested groups and queries
+func validateConditions(conditions []map[string]any) error {
+	for _, m := range conditions {
+		for _, v := range m {
+			var err error
+			switch vi := v.(type) {
+			case whereGroup:
+				err = validateConditions(vi)
+			case subQuery:
+				err = vi.b.validate()
+			case *rawExpr:
+				err = vi.err
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Raw starts a new query of custom select stmt with args bound to ? or $n placeholders, so it can be scanned
+// into structs by ScanStruct/EachToStruct or nested into another query like Table does, ex.:
+// Raw("SELECT name FROM users WHERE points > ? AND name != ?", 100, "Alex").EachToStruct(fn)
+//
+// If args are passed, every placeholder must be bound by them and every arg must be referenced, otherwise
+// ToSQL and the methods running the query return an error instead of sending stmt as is, as it was done before,
+// without args ? and $n are left as is and only ?? is unescaped.
+func (r *DB) Raw(sql string, args ...any) *DB {
+	t := r.Table("")
+	t.Builder.raw = newRawExpr(sql, args)
+	return t
+}
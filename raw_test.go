@@ -0,0 +1,108 @@
+package buildsqlx
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_RawBindings(t *testing.T) {
+	query, args, err := db.Table(UsersTable).SelectRaw("name, points * ? AS bonus", 2).AddSelect("id").
+		WhereRaw("LENGTH(name) > ?", 5).AndWhere("points", ">", 10).OrWhereRaw("id IN (?) AND name != $2", []any{1, 2}, "Alex").
+		GroupBy("id, name").HavingRaw("SUM(points) > $1", 100).OrderByRaw("points <-> ?", 50).ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT name, points * $1 AS bonus, id FROM "test_users" WHERE LENGTH(name) > $2 AND points > $3 `+
+		`OR id IN ($4, $5) AND name != $6 GROUP BY id, name HAVING SUM(points) > $7 ORDER BY points <-> $8`, query)
+	require.Equal(t, []any{2, 5, 10, 1, 2, "Alex", 100, 50}, args)
+
+	// placeholders in literals and of fragments without args are left as is, ?? is unescaped either way
+	query, args, err = db.Table(UsersTable).WhereRaw("settings ?? 'key' AND name != '?' AND points > ?", 10).
+		AndWhereRaw("settings ? 'key'").AndWhereRaw("settings ?? 'id'").AndWhere("id", "=", 1).ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT * FROM "test_users" WHERE settings ? 'key' AND name != '?' AND points > $1 `+
+		`AND settings ? 'key' AND settings ? 'id' AND id = $2`, query)
+	require.Equal(t, []any{10, 1}, args)
+
+	mysqlDb := NewDb(NewConnectionFromDb(&sql.DB{}), WithDialect(MySQL{}))
+	query, args, err = mysqlDb.Table(UsersTable).Where("id", ">", 1).AndWhereRaw("points BETWEEN $2 AND $1", 10, 20).ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM `test_users` WHERE id > ? AND points BETWEEN ? AND ?", query)
+	require.Equal(t, []any{1, 20, 10}, args)
+}
+
+func TestDB_RawBindingsMismatch(t *testing.T) {
+	_, _, err := db.Table(UsersTable).WhereRaw("points > ? AND id = ?", 10).ToSQL()
+	require.EqualError(t, err, "sql: raw expression 'points > ? AND id = ?' has more placeholders than 1 args")
+
+	_, _, err = db.Table(UsersTable).Where("id", "=", 1).OrWhereRaw("points > ?", 10, 20).ToSQL()
+	require.EqualError(t, err, "sql: raw expression 'points > ?' has no placeholder for arg 2")
+
+	_, _, err = db.Table(UsersTable).HavingRaw("SUM(points) > $3", 10, 20).CountSQL()
+	require.EqualError(t, err, "sql: raw expression 'SUM(points) > $3' refers to $3 out of 2 args")
+
+	_, _, err = db.Table(UsersTable).WhereRaw("id IN (?)", []any{}).DeleteSQL()
+	require.EqualError(t, err, "sql: raw expression 'id IN (?)' binds empty slice to placeholder of arg 1")
+
+	// nested queries are checked as well
+	_, _, err = db.Table(UsersTable).WhereExists(db.Raw("SELECT 1 FROM test_posts WHERE user_id = ? AND id = ?", 1)).ExistsSQL()
+	require.EqualError(t, err, "sql: raw expression 'SELECT 1 FROM test_posts WHERE user_id = ? AND id = ?' has more placeholders than 1 args")
+
+	_, _, err = db.FromSub(db.Raw("SELECT id FROM test_posts WHERE user_id = ?", 1, 2), "p").ToSQL()
+	require.EqualError(t, err, "sql: raw expression 'SELECT id FROM test_posts WHERE user_id = ?' has no placeholder for arg 2")
+
+	// execution paths fail before reaching the database
+	_, err = db.Table(UsersTable).WhereRaw("id = 1").AndWhereRaw("points > ?", 1, 2).Delete()
+	require.EqualError(t, err, "sql: raw expression 'points > ?' has no placeholder for arg 2")
+}
+
+func TestDB_Raw(t *testing.T) {
+	query, args, err := db.Raw("SELECT name FROM test_users WHERE points > ? AND name != ?", 100, "Alex").ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT name FROM test_users WHERE points > $1 AND name != $2", query)
+	require.Equal(t, []any{100, "Alex"}, args)
+
+	// raw query is renumbered when nested into another one
+	query, args, err = db.FromSub(db.Raw("SELECT id FROM test_posts WHERE user_id = $1", 3), "p").
+		Where("id", ">", 1).ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT * FROM (SELECT id FROM test_posts WHERE user_id = $1) AS "p" WHERE id > $2`, query)
+	require.Equal(t, []any{3, 1}, args)
+
+	_, _, err = db.Raw("SELECT 1").CountSQL()
+	require.Equal(t, errTableCallBeforeOp, err)
+}
+
+func TestDB_RawQuery(t *testing.T) {
+	_, err := db.Truncate(UsersTable)
+	require.NoError(t, err)
+
+	err = db.Table(UsersTable).InsertBatch(batchUsers)
+	require.NoError(t, err)
+
+	var res []User
+	user := &User{}
+	err = db.Raw("SELECT id, name, points FROM "+UsersTable+" WHERE points > ? AND name != ? ORDER BY id", 1000, TestUserName).
+		EachToStruct(func(rows *sql.Rows) error {
+			err := db.Next(rows, user)
+			if err == nil {
+				res = append(res, *user)
+			}
+
+			return err
+		})
+	require.NoError(t, err)
+	require.Equal(t, []User{{ID: 2, Name: "Darth Vader", Points: 1234}}, res)
+
+	err = db.Raw("SELECT name FROM "+UsersTable+" WHERE id = $1", 1).ScanStruct(user)
+	require.NoError(t, err)
+	require.Equal(t, "Alex Shmidt", user.Name)
+
+	cnt, err := db.Table(UsersTable).WhereRaw("points > ?", 100).AndWhere("name", "=", TestUserName).
+		OrWhereRaw("id = ?", 1).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(3), cnt)
+
+	_, err = db.Truncate(UsersTable)
+	require.NoError(t, err)
+}
@@ -0,0 +1,114 @@
+package buildsqlx
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RetryPolicy defines how InTransactionRetry reruns transaction failed by retryable error
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of transaction runs including the 1st one, 3 is used if it's <= 0
+	MaxAttempts int
+	// BaseDelay is the backoff before the 2nd run, doubled for every next run, 10ms is used if it's <= 0
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff, 1s is used if it's <= 0
+	MaxDelay time.Duration
+	// Retryable reports whether transaction failed by err should be rerun, IsRetryable is used if it's nil
+	Retryable func(err error) bool
+	// TxOptions transaction is started with e.g.: SERIALIZABLE isolation level, nil means driver defaults
+	TxOptions *TxOptions
+}
+
+// DefaultRetryPolicy retries serialization failures and deadlocks 3 times at most
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond, MaxDelay: time.Second}
+
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// IsRetryable reports whether err is serialization failure (40001) or deadlock (40P01),
+// so the whole transaction should be rerun
+func IsRetryable(err error) bool {
+	var code string
+
+	var pqErr *pq.Error
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &pqErr) {
+		code = string(pqErr.Code)
+	} else if errors.As(err, &stateErr) {
+		code = stateErr.SQLState()
+	}
+
+	return code == sqlStateSerializationFailure || code == sqlStateDeadlockDetected
+}
+
+// InTransactionRetry executes fn in transaction mode as InTransaction does switching db itself into transaction,
+// rerunning the whole transaction with backoff while it fails by retryable error
+func (r *DB) InTransactionRetry(policy RetryPolicy, fn func() (any, error)) error {
+	return r.InTransactionRetryCtx(context.Background(), policy, fn)
+}
+
+// InTransactionRetryCtx executes fn in transaction mode as InTransactionCtx does,
+// rerunning the whole transaction with backoff while it fails by retryable error and the context isn't done,
+// being called in transaction mode it runs fn within a savepoint once
+func (r *DB) InTransactionRetryCtx(ctx context.Context, policy RetryPolicy, fn func() (any, error)) error {
+	if r.Txn != nil { // the failed transaction can be rerun only as a whole by the outermost call
+		return r.runSavepoint(ctx, fn)
+	}
+
+	policy = policy.withDefaults()
+
+	for attempt := 1; ; attempt++ {
+		err := r.runTransaction(ctx, attempt, policy.TxOptions, fn)
+		if err == nil || attempt >= policy.MaxAttempts || !policy.Retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(policy.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// withDefaults returns policy with zero values replaced by defaults
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+
+	if p.Retryable == nil {
+		p.Retryable = IsRetryable
+	}
+
+	return p
+}
+
+// backoff returns delay after failed attempt - exponential one capped by MaxDelay,
+// randomized within its upper half, so concurrent transactions don't collide again
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.MaxDelay
+	if shift := uint(attempt - 1); shift < 63 && p.BaseDelay <= p.MaxDelay>>shift {
+		delay = p.BaseDelay << shift
+	}
+
+	half := delay / 2
+
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
@@ -0,0 +1,115 @@
+package buildsqlx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRetryable(t *testing.T) {
+	require.True(t, IsRetryable(&pq.Error{Code: "40001"}))
+	require.True(t, IsRetryable(fmt.Errorf("update: %w", &pq.Error{Code: "40P01"})))
+	require.False(t, IsRetryable(&pq.Error{Code: "23505"}))
+	require.False(t, IsRetryable(errors.New("some err")))
+	require.False(t, IsRetryable(nil))
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}.withDefaults()
+	require.Equal(t, 3, p.MaxAttempts)
+
+	for attempt, max := range map[int]time.Duration{1: 10, 2: 20, 3: 40, 4: 50, 100: 50} {
+		for i := 0; i < 10; i++ {
+			d := p.backoff(attempt)
+			require.True(t, d >= max*time.Millisecond/2 && d <= max*time.Millisecond, "attempt %d: %s", attempt, d)
+		}
+	}
+}
+
+func TestDB_InTransactionRetry(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rdb := NewDb(NewConnection("postgres", dbConnInfo), WithLogger(slog.New(slog.NewTextHandler(buf, nil)), WithLogLevel(slog.LevelInfo)))
+
+	_, err := rdb.Truncate(TestTable)
+	require.NoError(t, err)
+
+	attempts := 0
+	err = rdb.InTransactionRetry(RetryPolicy{BaseDelay: time.Millisecond}, func() (any, error) {
+		attempts++
+		id, err := rdb.Table(TestTable).InsertGetId(data)
+		if err != nil {
+			return nil, err
+		}
+
+		if attempts < 3 {
+			return nil, &pq.Error{Code: "40001", Message: "could not serialize access"}
+		}
+
+		return id, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+
+	// only the last attempt has been committed
+	cnt, err := rdb.Table(TestTable).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), cnt)
+	require.Equal(t, 3, strings.Count(buf.String(), `msg="sql transaction"`))
+	require.Contains(t, buf.String(), "committed=true attempt=3")
+
+	// not retryable error and exhausted attempts are returned as is
+	attempts = 0
+	err = rdb.InTransactionRetry(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}, func() (any, error) {
+		attempts++
+		return nil, &pq.Error{Code: "40P01"}
+	})
+	require.True(t, IsRetryable(err))
+	require.Equal(t, 2, attempts)
+
+	attempts = 0
+	err = rdb.InTransactionRetry(DefaultRetryPolicy, func() (any, error) {
+		attempts++
+		return nil, errors.New("some err")
+	})
+	require.EqualError(t, err, "some err")
+	require.Equal(t, 1, attempts)
+
+	// only the row matching where conditions is decremented by the committed attempt
+	_, err = rdb.Truncate(TestTable)
+	require.NoError(t, err)
+
+	balance := int64(500)
+	err = rdb.Table(TestTable).InsertBatch([]DataStruct{{Foo: "a", Bar: "a", Baz: &balance}, {Foo: "b", Bar: "b", Baz: &balance}})
+	require.NoError(t, err)
+
+	attempts = 0
+	err = rdb.InTransactionRetry(DefaultRetryPolicy, func() (any, error) {
+		attempts++
+		res, err := rdb.Table(TestTable).Where("foo", "=", "a").Decrement("baz", 100)
+		if err == nil && attempts < 2 {
+			return nil, &pq.Error{Code: "40001"}
+		}
+
+		return res, err
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+
+	sum, err := rdb.Table(TestTable).Where("foo", "=", "a").Sum("baz")
+	require.NoError(t, err)
+	require.Equal(t, float64(400), sum)
+
+	sum, err = rdb.Table(TestTable).Where("foo", "=", "b").Sum("baz")
+	require.NoError(t, err)
+	require.Equal(t, float64(500), sum)
+
+	_, err = rdb.Truncate(TestTable)
+	require.NoError(t, err)
+}
@@ -1,10 +1,14 @@
 package buildsqlx
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // column types
@@ -81,7 +85,10 @@ type column struct {
 	RenameTo        *string
 	ColumnType      colType
 	Default         *string
+	IsDefaultValue  bool
+	DefaultValue    any
 	ForeignKey      *string
+	References      *string
 	IdxName         string
 	NewIdxName      string
 	Comment         *string
@@ -91,24 +98,34 @@ type column struct {
 
 // Schema creates and/or manipulates table structure with an appropriate types/indices/comments/defaults/nulls etc
 func (r *DB) Schema(tblName string, fn func(table *Table) error) (res sql.Result, err error) {
+	return r.SchemaCtx(context.Background(), tblName, fn)
+}
+
+// SchemaCtx creates and/or manipulates table structure with the given context
+func (r *DB) SchemaCtx(ctx context.Context, tblName string, fn func(table *Table) error) (res sql.Result, err error) {
 	tbl := &Table{tblName: tblName}
 	err = fn(tbl) // run fn with Table struct passed to collect columns to []*column slice
 	if err != nil {
 		return nil, err
 	}
 
+	g := r.Builder.dialect.SchemaGrammar()
+	if err = validateGrammar(r.Builder.dialect.Name(), g, tbl); err != nil {
+		return nil, err
+	}
+
 	l := len(tbl.columns)
 	if l > 0 {
-		tblExists, err := r.HasTable(DefaultSchema, tblName)
+		tblExists, err := r.HasTableCtx(ctx, g.DefaultSchema, tblName)
 		if err != nil {
 			return nil, err
 		}
 
 		if tblExists { // modify tbl by adding/modifying/deleting columns/indices
-			return r.modifyTable(tbl)
+			return r.modifyTable(ctx, tbl)
 		}
 		// create table with relative columns/indices
-		return r.createTable(tbl)
+		return r.createTable(ctx, tbl)
 	}
 
 	return
@@ -116,26 +133,35 @@ func (r *DB) Schema(tblName string, fn func(table *Table) error) (res sql.Result
 
 // SchemaIfNotExists creates table structure if not exists with an appropriate types/indices/comments/defaults/nulls etc
 func (r *DB) SchemaIfNotExists(tblName string, fn func(table *Table) error) (res sql.Result, err error) {
+	return r.SchemaIfNotExistsCtx(context.Background(), tblName, fn)
+}
+
+// SchemaIfNotExistsCtx creates table structure if not exists with the given context
+func (r *DB) SchemaIfNotExistsCtx(ctx context.Context, tblName string, fn func(table *Table) error) (res sql.Result, err error) {
 	tbl := &Table{tblName: tblName}
 	err = fn(tbl) // run fn with Table struct passed to collect columns to []*column slice
 	if err != nil {
 		return nil, err
 	}
 
+	if err = validateGrammar(r.Builder.dialect.Name(), r.Builder.dialect.SchemaGrammar(), tbl); err != nil {
+		return nil, err
+	}
+
 	l := len(tbl.columns)
 	if l > 0 {
 		// create table with relative columns/indices
 		tbl.ifExists = IfNotExists
-		return r.createTable(tbl)
+		return r.createTable(ctx, tbl)
 	}
 
 	return
 }
 
-func (r *DB) createIndices(indices []string) (res sql.Result, err error) {
+func (r *DB) createIndices(ctx context.Context, tblName string, indices []string) (res sql.Result, err error) {
 	for _, idx := range indices {
 		if idx != "" {
-			res, err = r.Sql().Exec(idx)
+			res, err = r.exec(ctx, OpDDL, tblName, idx)
 			if err != nil {
 				return nil, err
 			}
@@ -144,10 +170,10 @@ func (r *DB) createIndices(indices []string) (res sql.Result, err error) {
 	return
 }
 
-func (r *DB) createComments(comments []string) (res sql.Result, err error) {
+func (r *DB) createComments(ctx context.Context, tblName string, comments []string) (res sql.Result, err error) {
 	for _, comment := range comments {
 		if comment != "" {
-			res, err = r.Sql().Exec(comment)
+			res, err = r.exec(ctx, OpDDL, tblName, comment)
 			if err != nil {
 				return nil, err
 			}
@@ -156,32 +182,52 @@ func (r *DB) createComments(comments []string) (res sql.Result, err error) {
 	return
 }
 
+// validateGrammar checks whether alterations collected in Table can be expressed by the dialect
+func validateGrammar(dialect string, g SchemaGrammar, t *Table) error {
+	for _, col := range t.columns {
+		if col.IsModify && col.RenameTo == nil && !g.AlterColumnType {
+			return fmt.Errorf("sql: %s dialect doesn't support column type modification for column '%s'", dialect, col.Name)
+		}
+
+		if col.NewIdxName != "" && !g.RenameIndex {
+			return fmt.Errorf("sql: %s dialect doesn't support index renaming for index '%s'", dialect, col.IdxName)
+		}
+	}
+
+	return nil
+}
+
 // builds column definition
-func composeColumn(col *column) string {
-	return col.Name + " " + string(col.ColumnType) + buildColumnOptions(col)
+func composeColumn(g SchemaGrammar, col *column) string {
+	return col.Name + " " + columnType(g, col) + buildColumnOptions(g, col)
 }
 
 // builds column definition
-func composeAddColumn(tblName string, col *column) string {
-	return columnDef(tblName, col, Add)
+func composeAddColumn(g SchemaGrammar, tblName string, col *column) string {
+	return columnDef(g, tblName, col, Add)
 }
 
 // builds column definition
-func composeModifyColumn(tblName string, col *column) string {
-	return columnDef(tblName, col, col.Op)
+func composeModifyColumn(g SchemaGrammar, tblName string, col *column) string {
+	return columnDef(g, tblName, col, col.Op)
 }
 
 // builds column definition
-func composeDrop(tblName string, col *column) string {
+func composeDrop(g SchemaGrammar, tblName string, col *column) string {
 	if col.IsIndex {
 		return dropIdxDef(col)
 	}
-	return columnDef(tblName, col, Drop)
+	return columnDef(g, tblName, col, Drop)
 }
 
 // concats all definition in 1 string expression
-func columnDef(tblName string, col *column, op string) (colDef string) {
-	colDef = AlterTable + tblName + op + "COLUMN " + applyExistence(col.IfExists) + col.Name
+func columnDef(g SchemaGrammar, tblName string, col *column, op string) (colDef string) {
+	ifExists := ""
+	if g.ColumnIfExists {
+		ifExists = applyExistence(col.IfExists)
+	}
+
+	colDef = AlterTable + tblName + op + "COLUMN " + ifExists + col.Name
 	if op == Rename {
 		return colDef + " TO " + *col.RenameTo
 	}
@@ -189,12 +235,24 @@ func columnDef(tblName string, col *column, op string) (colDef string) {
 		colDef += " TYPE "
 	}
 	if op != Drop {
-		colDef += " " + string(col.ColumnType) + buildColumnOptions(col)
+		colDef += " " + columnType(g, col) + buildColumnOptions(g, col)
 	}
 
 	return
 }
 
+// columnType replaces auto incremented types with the dialect specific ones
+func columnType(g SchemaGrammar, col *column) string {
+	switch col.ColumnType {
+	case TypeSerial:
+		return g.Serial
+	case TypeBigSerial:
+		return g.BigSerial
+	}
+
+	return string(col.ColumnType)
+}
+
 func applyExistence(ifExists uint) string {
 	if ifExists == IfExistsUndeclared {
 		return ""
@@ -211,7 +269,7 @@ func dropIdxDef(col *column) string {
 	return "DROP INDEX " + applyExistence(col.IfExists) + col.IdxName
 }
 
-func buildColumnOptions(col *column) (colSchema string) {
+func buildColumnOptions(g SchemaGrammar, col *column) (colSchema string) {
 	if col.IsPrimaryKey {
 		colSchema += " PRIMARY KEY"
 	}
@@ -220,21 +278,89 @@ func buildColumnOptions(col *column) (colSchema string) {
 		colSchema += " NOT NULL"
 	}
 
-	if col.Default != nil {
-		colSchema += " DEFAULT " + *col.Default
+	if col.IsDefaultValue {
+		colSchema += " DEFAULT " + composeLiteral(g, col.DefaultValue)
+	} else if col.Default != nil {
+		def := *col.Default
+		if def == CurrentDateTime {
+			def = g.CurrentDateTime
+		}
+		colSchema += " DEFAULT " + def
 	}
 
 	if col.Collation != nil {
 		colSchema += " COLLATE \"" + *col.Collation + "\""
 	}
+
+	if col.References != nil && !g.AddConstraint {
+		colSchema += " " + *col.References
+	}
 	return
 }
 
+// composeLiteral builds literal of val for DDL stmt, where values can't be bound as parameters
+func composeLiteral(g SchemaGrammar, val any) string {
+	if v, ok := val.(driver.Valuer); ok {
+		if dv, err := v.Value(); err == nil {
+			val = dv
+		}
+	}
+
+	switch v := val.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return quoteLiteral(g, string(v))
+	case time.Time:
+		return quoteLiteral(g, v.Format("2006-01-02 15:04:05.999999-07:00"))
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.String:
+		return quoteLiteral(g, rv.String())
+	case reflect.Bool:
+		if rv.Bool() {
+			return "TRUE"
+		}
+		return "FALSE"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 64)
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "NULL"
+		}
+		return composeLiteral(g, rv.Elem().Interface())
+	}
+
+	return quoteLiteral(g, fmt.Sprint(val))
+}
+
+// quoteLiteral wraps string into single quotes doubling the quotes inside and backslashes if they're escape chars
+func quoteLiteral(g SchemaGrammar, v string) string {
+	if g.BackslashEscapes {
+		v = strings.ReplaceAll(v, `\`, `\\`)
+	}
+
+	return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+}
+
 // build index for table on particular column depending on an index type
-func composeIndex(tblName string, col *column) string {
+func composeIndex(g SchemaGrammar, tblName string, col *column) string {
+	isIdxConcurrent, includes := col.IsIdxConcurrent, col.Includes
+	if !g.IndexOptions {
+		isIdxConcurrent, includes = false, nil
+	}
+
 	if col.IsIndex && col.NewIdxName == "" {
-		return "CREATE INDEX " + applyIdxConcurrency(col.IsIdxConcurrent) + applyExistence(col.IfExists) +
-			col.IdxName + " ON " + tblName + " (" + col.Name + ")" + applyIncludes(col.Includes)
+		return "CREATE INDEX " + applyIdxConcurrency(isIdxConcurrent) + applyExistence(col.IfExists) +
+			col.IdxName + " ON " + tblName + " (" + col.Name + ")" + applyIncludes(includes)
 	}
 
 	if col.NewIdxName != "" {
@@ -242,12 +368,12 @@ func composeIndex(tblName string, col *column) string {
 	}
 
 	if col.IsUnique {
-		return "CREATE UNIQUE INDEX " + applyIdxConcurrency(col.IsIdxConcurrent) + applyExistence(col.IfExists) +
-			col.IdxName + " ON " + tblName + " (" + col.Name + ")" + applyIncludes(col.Includes)
+		return "CREATE UNIQUE INDEX " + applyIdxConcurrency(isIdxConcurrent) + applyExistence(col.IfExists) +
+			col.IdxName + " ON " + tblName + " (" + col.Name + ")" + applyIncludes(includes)
 	}
 
-	if col.ForeignKey != nil {
-		if col.IsIdxConcurrent {
+	if col.ForeignKey != nil && g.AddConstraint {
+		if isIdxConcurrent {
 			concurrentFk := ""
 			words := strings.Fields(*col.ForeignKey)
 			for _, word := range words {
@@ -292,16 +418,16 @@ func applyIncludes(includes []string) string {
 	return ""
 }
 
-func composeComment(tblName string, col *column) string {
-	if col.Comment != nil {
-		return "COMMENT ON COLUMN " + tblName + "." + col.Name + " IS '" + *col.Comment + "'"
+func composeComment(g SchemaGrammar, tblName string, col *column) string {
+	if col.Comment != nil && g.Comments {
+		return "COMMENT ON COLUMN " + tblName + "." + col.Name + " IS " + quoteLiteral(g, *col.Comment)
 	}
 	return ""
 }
 
-func (t *Table) composeTableComment() string {
-	if t.comment != nil {
-		return "COMMENT ON TABLE " + t.tblName + " IS '" + *t.comment + "'"
+func (t *Table) composeTableComment(g SchemaGrammar) string {
+	if t.comment != nil && g.Comments {
+		return "COMMENT ON TABLE " + t.tblName + " IS " + quoteLiteral(g, *t.comment)
 	}
 	return ""
 }
@@ -389,10 +515,10 @@ func (t *Table) Collation(coll string) *Table {
 	return t
 }
 
-// Default sets the default column value
+// Default sets the default column value, which is escaped as literal of the dialect e.g. quotes of strings are doubled
 func (t *Table) Default(val interface{}) *Table {
-	v := convertToStr(val)
-	t.columns[len(t.columns)-1].Default = &v
+	t.columns[len(t.columns)-1].IsDefaultValue = true
+	t.columns[len(t.columns)-1].DefaultValue = val
 	return t
 }
 
@@ -431,7 +557,9 @@ func (t *Table) Unique(idxName string) *Table {
 // ForeignKey sets the last column to reference rfcTbl on onCol with idxName foreign key index
 func (t *Table) ForeignKey(idxName, rfcTbl, onCol string) *Table {
 	key := AlterTable + t.tblName + " ADD CONSTRAINT " + idxName + " FOREIGN KEY (" + t.columns[len(t.columns)-1].Name + ") REFERENCES " + rfcTbl + " (" + onCol + ")"
+	ref := "CONSTRAINT " + idxName + " REFERENCES " + rfcTbl + " (" + onCol + ")"
 	t.columns[len(t.columns)-1].ForeignKey = &key
+	t.columns[len(t.columns)-1].References = &ref
 	return t
 }
 
@@ -550,35 +678,36 @@ func (t *Table) DropIndex(idxNm string) *Table {
 }
 
 // createTable create table with relative columns/indices
-func (r *DB) createTable(t *Table) (res sql.Result, err error) {
+func (r *DB) createTable(ctx context.Context, t *Table) (res sql.Result, err error) {
+	g := r.Builder.dialect.SchemaGrammar()
 	l := len(t.columns)
 	var indices []string
 	var comments []string
 
 	query := "CREATE TABLE " + applyExistence(t.ifExists) + t.tblName + "("
 	for k, col := range t.columns {
-		query += composeColumn(col)
+		query += composeColumn(g, col)
 		if k < l-1 {
 			query += ","
 		}
-		indices = append(indices, composeIndex(t.tblName, col))
-		comments = append(comments, composeComment(t.tblName, col))
+		indices = append(indices, composeIndex(g, t.tblName, col))
+		comments = append(comments, composeComment(g, t.tblName, col))
 	}
 	query += ")"
 
-	res, err = r.Sql().Exec(query)
+	res, err = r.exec(ctx, OpDDL, t.tblName, query)
 	if err != nil {
 		return nil, err
 	}
 
 	// create indices
-	_, err = r.createIndices(indices)
+	_, err = r.createIndices(ctx, t.tblName, indices)
 	if err != nil {
 		return nil, err
 	}
 	// create comments
-	comments = append(comments, t.composeTableComment())
-	_, err = r.createComments(comments)
+	comments = append(comments, t.composeTableComment(g))
+	_, err = r.createComments(ctx, t.tblName, comments)
 	if err != nil {
 		return nil, err
 	}
@@ -586,7 +715,8 @@ func (r *DB) createTable(t *Table) (res sql.Result, err error) {
 }
 
 // adds, modifies or deletes column
-func (r *DB) modifyTable(t *Table) (res sql.Result, err error) {
+func (r *DB) modifyTable(ctx context.Context, t *Table) (res sql.Result, err error) {
+	g := r.Builder.dialect.SchemaGrammar()
 	l := len(t.columns)
 
 	var indices []string
@@ -598,17 +728,24 @@ func (r *DB) modifyTable(t *Table) (res sql.Result, err error) {
 			if col.RenameTo != nil {
 				col.Op = Rename
 			}
-			query += composeModifyColumn(t.tblName, col)
+			query += composeModifyColumn(g, t.tblName, col)
 		} else if col.IsDrop {
-			query += composeDrop(t.tblName, col)
+			isCol := true
+			if !col.IsIndex && col.IfExists == IfExists && !g.ColumnIfExists { // emulate DROP COLUMN IF EXISTS
+				isCol, _ = r.HasColumnsCtx(ctx, g.DefaultSchema, t.tblName, col.Name)
+			}
+
+			if isCol {
+				query += composeDrop(g, t.tblName, col)
+			}
 		} else { // create new column/comment/index or just add comments indices
-			isCol, _ := r.HasColumns(DefaultSchema, t.tblName, col.Name)
+			isCol, _ := r.HasColumnsCtx(ctx, g.DefaultSchema, t.tblName, col.Name)
 			if !isCol && col.NewIdxName == "" {
-				query += composeAddColumn(t.tblName, col)
+				query += composeAddColumn(g, t.tblName, col)
 			}
 
-			indices = append(indices, composeIndex(t.tblName, col))
-			comments = append(comments, composeComment(t.tblName, col))
+			indices = append(indices, composeIndex(g, t.tblName, col))
+			comments = append(comments, composeComment(g, t.tblName, col))
 		}
 
 		if k < l-1 {
@@ -616,18 +753,18 @@ func (r *DB) modifyTable(t *Table) (res sql.Result, err error) {
 		}
 	}
 
-	res, err = r.Sql().Exec(query)
+	res, err = r.exec(ctx, OpDDL, t.tblName, query)
 	if err != nil {
 		return nil, err
 	}
 
 	// create indices
-	_, err = r.createIndices(indices)
+	_, err = r.createIndices(ctx, t.tblName, indices)
 	if err != nil {
 		return nil, err
 	}
 	// create comments
-	_, err = r.createComments(comments)
+	_, err = r.createComments(ctx, t.tblName, comments)
 	if err != nil {
 		return nil, err
 	}
@@ -1,8 +1,10 @@
 package buildsqlx
 
 import (
+	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -166,3 +168,55 @@ func TestTable_DateTime(t *testing.T) {
 	_, err = db.Drop(TableToCreate)
 	require.NoError(t, err)
 }
+
+func TestTable_SQLiteGrammar(t *testing.T) {
+	g := SQLite{}.SchemaGrammar()
+	tbl := &Table{tblName: TableToCreate}
+	tbl.Increments("id")
+	tbl.Integer("user_id").ForeignKey("fk_user_id", UsersTable, "id")
+	tbl.String("title", 64).Index("idx_title").Concurrently().Include("id")
+	tbl.DateTime("created_at", true).Comment("creation time")
+
+	require.Equal(t, "id INTEGER PRIMARY KEY", composeColumn(g, tbl.columns[0]))
+	require.Equal(t, "user_id INTEGER CONSTRAINT fk_user_id REFERENCES test_users (id)", composeColumn(g, tbl.columns[1]))
+	require.Empty(t, composeIndex(g, TableToCreate, tbl.columns[1]))
+	require.Equal(t, "CREATE INDEX idx_title ON big_tbl (title)", composeIndex(g, TableToCreate, tbl.columns[2]))
+	require.Equal(t, "created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP", composeColumn(g, tbl.columns[3]))
+	require.Empty(t, composeComment(g, TableToCreate, tbl.columns[3]))
+
+	tbl.String("title", 128).Change()
+	require.EqualError(t, validateGrammar("sqlite", g, tbl), "sql: sqlite dialect doesn't support column type modification for column 'title'")
+}
+
+func TestTable_Default(t *testing.T) {
+	tbl := &Table{tblName: TableToCreate}
+	tbl.String("title", 64).Default(`it's a 'title' \ path`)
+	tbl.Boolean("is_active").Default(true)
+	tbl.Integer("cnt").Default(int32(-3))
+	tbl.DblPrecision("rate").Default(float32(1.1))
+	tbl.DateTime("created_at", false).Default(time.Date(2020, 1, 2, 3, 4, 5, 600000000, time.UTC))
+	tbl.String("note", 64).Default(nil)
+	tbl.String("nick", 64).Default(sql.NullString{String: "nick", Valid: true})
+
+	g := Postgres{}.SchemaGrammar()
+	require.Equal(t, `title VARCHAR(64) DEFAULT 'it''s a ''title'' \ path'`, composeColumn(g, tbl.columns[0]))
+	require.Equal(t, "is_active BOOLEAN DEFAULT TRUE", composeColumn(g, tbl.columns[1]))
+	require.Equal(t, "cnt INTEGER DEFAULT -3", composeColumn(g, tbl.columns[2]))
+	require.Equal(t, "rate DOUBLE PRECISION DEFAULT 1.1", composeColumn(g, tbl.columns[3]))
+	require.Equal(t, "created_at TIMESTAMP DEFAULT '2020-01-02 03:04:05.6+00:00'", composeColumn(g, tbl.columns[4]))
+	require.Equal(t, "note VARCHAR(64) DEFAULT NULL", composeColumn(g, tbl.columns[5]))
+	require.Equal(t, "nick VARCHAR(64) DEFAULT 'nick'", composeColumn(g, tbl.columns[6]))
+
+	g = MySQL{}.SchemaGrammar()
+	require.Equal(t, `title VARCHAR(64) DEFAULT 'it''s a ''title'' \\ path'`, composeColumn(g, tbl.columns[0]))
+}
+
+func TestTable_CommentEscaping(t *testing.T) {
+	tbl := &Table{tblName: TableToCreate}
+	tbl.String("title", 64).Comment(`it's a 'title'`)
+	tbl.TableComment("big table'); DROP TABLE test_users; --")
+
+	g := Postgres{}.SchemaGrammar()
+	require.Equal(t, `COMMENT ON COLUMN big_tbl.title IS 'it''s a ''title'''`, composeComment(g, TableToCreate, tbl.columns[0]))
+	require.Equal(t, `COMMENT ON TABLE big_tbl IS 'big table''); DROP TABLE test_users; --'`, tbl.composeTableComment(g))
+}
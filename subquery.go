@@ -0,0 +1,112 @@
+package buildsqlx
+
+// subQuery is a select query nested into another one, its placeholders are numbered on building the outer query
+// and its values are bound in order of the placeholders
+type subQuery struct {
+	b     *builder
+	alias string
+}
+
+// sub returns a copy of q to be nested, so further changes of q don't affect the outer query
+func sub(q *DB, alias string) subQuery {
+	return subQuery{b: q.Builder.clone(), alias: alias}
+}
+
+// FromSub starts a new query like Table does, but selects from the result of q aliased as table, ex.:
+// FromSub(db.Table("posts").Select("user_id").Where("status", "=", 1), "p") for SELECT * FROM (SELECT user_id FROM "posts" WHERE status = $1) AS "p"
+func (r *DB) FromSub(q *DB, alias string) *DB {
+	s := sub(q, alias)
+	t := r.Table(alias)
+	t.Builder.fromSub = &s
+	return t
+}
+
+// SelectSub adds the result of q aliased as column to select, ex.:
+// SelectSub(db.Table("posts").Select("COUNT(*)").WhereColumn("posts.user_id", "=", "users.id"), "posts_count")
+func (r *DB) SelectSub(q *DB, alias string) *DB {
+	r.Builder.selectSubs = append(r.Builder.selectSubs, sub(q, alias))
+	return r
+}
+
+// WhereSub compares column with the result of q e.g. WhereSub("points", ">", db.Table("users").Select("AVG(points)"))
+func (r *DB) WhereSub(column, operator string, q *DB) *DB {
+	return r.buildWhere("", column, operator, sub(q, ""))
+}
+
+// AndWhereSub compares column with the result of q with AND logical operator
+func (r *DB) AndWhereSub(column, operator string, q *DB) *DB {
+	return r.buildWhere(sqlOperatorAnd, column, operator, sub(q, ""))
+}
+
+// OrWhereSub compares column with the result of q with OR logical operator
+func (r *DB) OrWhereSub(column, operator string, q *DB) *DB {
+	return r.buildWhere(sqlOperatorOr, column, operator, sub(q, ""))
+}
+
+// WhereInSub appends column IN (SELECT ...) stmt of q to WHERE clause
+func (r *DB) WhereInSub(column string, q *DB) *DB {
+	return r.buildWhere("", column, "IN", sub(q, ""))
+}
+
+// AndWhereInSub appends AND column IN (SELECT ...) stmt of q to WHERE clause
+func (r *DB) AndWhereInSub(column string, q *DB) *DB {
+	return r.buildWhere(sqlOperatorAnd, column, "IN", sub(q, ""))
+}
+
+// OrWhereInSub appends OR column IN (SELECT ...) stmt of q to WHERE clause
+func (r *DB) OrWhereInSub(column string, q *DB) *DB {
+	return r.buildWhere(sqlOperatorOr, column, "IN", sub(q, ""))
+}
+
+// WhereNotInSub appends column NOT IN (SELECT ...) stmt of q to WHERE clause
+func (r *DB) WhereNotInSub(column string, q *DB) *DB {
+	return r.buildWhere("", column, "NOT IN", sub(q, ""))
+}
+
+// AndWhereNotInSub appends AND column NOT IN (SELECT ...) stmt of q to WHERE clause
+func (r *DB) AndWhereNotInSub(column string, q *DB) *DB {
+	return r.buildWhere(sqlOperatorAnd, column, "NOT IN", sub(q, ""))
+}
+
+// OrWhereNotInSub appends OR column NOT IN (SELECT ...) stmt of q to WHERE clause
+func (r *DB) OrWhereNotInSub(column string, q *DB) *DB {
+	return r.buildWhere(sqlOperatorOr, column, "NOT IN", sub(q, ""))
+}
+
+// WhereExists appends EXISTS (SELECT ...) stmt of q to WHERE clause
+func (r *DB) WhereExists(q *DB) *DB {
+	return r.buildWhereExists("", "EXISTS", q)
+}
+
+// AndWhereExists appends AND EXISTS (SELECT ...) stmt of q to WHERE clause
+func (r *DB) AndWhereExists(q *DB) *DB {
+	return r.buildWhereExists(sqlOperatorAnd, "EXISTS", q)
+}
+
+// OrWhereExists appends OR EXISTS (SELECT ...) stmt of q to WHERE clause
+func (r *DB) OrWhereExists(q *DB) *DB {
+	return r.buildWhereExists(sqlOperatorOr, "EXISTS", q)
+}
+
+// WhereNotExists appends NOT EXISTS (SELECT ...) stmt of q to WHERE clause
+func (r *DB) WhereNotExists(q *DB) *DB {
+	return r.buildWhereExists("", "NOT EXISTS", q)
+}
+
+// AndWhereNotExists appends AND NOT EXISTS (SELECT ...) stmt of q to WHERE clause
+func (r *DB) AndWhereNotExists(q *DB) *DB {
+	return r.buildWhereExists(sqlOperatorAnd, "NOT EXISTS", q)
+}
+
+// OrWhereNotExists appends OR NOT EXISTS (SELECT ...) stmt of q to WHERE clause
+func (r *DB) OrWhereNotExists(q *DB) *DB {
+	return r.buildWhereExists(sqlOperatorOr, "NOT EXISTS", q)
+}
+
+func (r *DB) buildWhereExists(prefix, operator string, q *DB) *DB {
+	if prefix != "" {
+		prefix = " " + prefix + " "
+	}
+	r.Builder.whereBindings = append(r.Builder.whereBindings, map[string]any{prefix + operator: sub(q, "")})
+	return r
+}
@@ -0,0 +1,302 @@
+package buildsqlx
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ToSQL compiles select query glued with Union/UnionAll if any into SQL with args without executing it
+func (r *DB) ToSQL() (string, []any, error) {
+	return r.Builder.selectSQL()
+}
+
+// InsertSQL compiles INSERT stmt for struct into SQL with args without executing it
+func (r *DB) InsertSQL(data any) (string, []any, error) {
+	return r.Builder.insertSQL(data)
+}
+
+// InsertGetIdSQL compiles INSERT stmt returning id for struct into SQL with args without executing it,
+// the id is got by LastInsertId for dialects without RETURNING, so the SQL is the same as InsertSQL returns
+func (r *DB) InsertGetIdSQL(data any) (string, []any, error) {
+	query, args, err := r.Builder.insertSQL(data)
+	if err != nil || !r.Builder.dialect.Returning() {
+		return query, args, err
+	}
+
+	return query + ` RETURNING id`, args, nil
+}
+
+// UpdateSQL compiles UPDATE stmt for struct with where/from clauses into SQL with args without executing it
+func (r *DB) UpdateSQL(data any) (string, []any, error) {
+	return r.Builder.updateSQL(data)
+}
+
+// DeleteSQL compiles DELETE stmt with where clause into SQL with args without executing it
+func (r *DB) DeleteSQL() (string, []any, error) {
+	return r.Builder.deleteSQL()
+}
+
+// InsertUsingSQL compiles INSERT stmt of rows selected by q into SQL with args without executing it
+func (r *DB) InsertUsingSQL(columns []string, q *DB) (string, []any, error) {
+	return r.Builder.insertUsingSQL(columns, q.Builder)
+}
+
+// ReplaceSQL compiles upsert stmt for struct into SQL with args without executing it
+func (r *DB) ReplaceSQL(data any, conflict string) (string, []any, error) {
+	return r.Builder.replaceSQL(data, conflict)
+}
+
+// ExistsSQL compiles query checking whether conditional rows exist into SQL with args without executing it
+func (r *DB) ExistsSQL() (string, []any, error) {
+	return r.Builder.existsSQL()
+}
+
+// CountSQL compiles query counting resulting rows into SQL with args without executing it
+func (r *DB) CountSQL() (string, []any, error) {
+	return r.Builder.aggregateSQL("COUNT(*)")
+}
+
+// AvgSQL compiles query calculating average for column into SQL with args without executing it
+func (r *DB) AvgSQL(column string) (string, []any, error) {
+	return r.Builder.aggregateSQL("AVG(" + column + ")")
+}
+
+// MinSQL compiles query calculating minimum for column into SQL with args without executing it
+func (r *DB) MinSQL(column string) (string, []any, error) {
+	return r.Builder.aggregateSQL("MIN(" + column + ")")
+}
+
+// MaxSQL compiles query calculating maximum for column into SQL with args without executing it
+func (r *DB) MaxSQL(column string) (string, []any, error) {
+	return r.Builder.aggregateSQL("MAX(" + column + ")")
+}
+
+// SumSQL compiles query calculating sum for column into SQL with args without executing it
+func (r *DB) SumSQL(column string) (string, []any, error) {
+	return r.Builder.aggregateSQL("SUM(" + column + ")")
+}
+
+// IncrementSQL compiles UPDATE stmt incrementing column on passed value with where/from clauses into SQL with args
+// without executing it
+func (r *DB) IncrementSQL(column string, on uint64) (string, []any, error) {
+	return r.Builder.incrDecrSQL(column, plusSign, on)
+}
+
+// DecrementSQL compiles UPDATE stmt decrementing column on passed value with where/from clauses into SQL with args
+// without executing it
+func (r *DB) DecrementSQL(column string, on uint64) (string, []any, error) {
+	return r.Builder.incrDecrSQL(column, minusSign, on)
+}
+
+// selectSQL builds select stmt glued with union selects
+func (r *builder) selectSQL() (string, []any, error) {
+	if r.table == "" && r.raw == nil {
+		return "", nil, errTableCallBeforeOp
+	}
+
+	if err := r.validate(); err != nil {
+		return "", nil, err
+	}
+
+	return r.buildQuery(), r.queryArgs(), nil
+}
+
+// insertSQL builds INSERT stmt for struct
+func (r *builder) insertSQL(data any) (string, []any, error) {
+	if r.table == "" {
+		return "", nil, errTableCallBeforeOp
+	}
+
+	if err := r.validate(); err != nil {
+		return "", nil, err
+	}
+
+	_, values, _ := prepareBindingsForStruct(r.dialect, data)
+	i := r.startBindingsAt
+
+	return r.compileInsert(data, &i), append(r.withArgs(), values...), nil
+}
+
+// compileInsert constructs INSERT stmt for struct with WITH clause if any numbering placeholders from i
+func (r *builder) compileInsert(data any, i *int) string {
+	with := r.compileWith(i)
+	columns, values, _ := prepareBindingsForStruct(r.dialect, data)
+
+	return with + r.insertStmt(columns, r.placeholders(len(values), i))
+}
+
+// insertStmt builds INSERT stmt of a single row
+func (r *builder) insertStmt(columns, bindings []string) string {
+	return `INSERT INTO ` + r.dialect.Quote(r.table) + ` (` + strings.Join(columns, `, `) + `) VALUES(` + strings.Join(bindings, `, `) + `)`
+}
+
+// insertUsingSQL builds INSERT stmt of rows selected by q
+func (r *builder) insertUsingSQL(columns []string, q *builder) (string, []any, error) {
+	if r.table == "" {
+		return "", nil, errTableCallBeforeOp
+	}
+
+	if err := r.validate(); err != nil {
+		return "", nil, err
+	}
+
+	if err := q.validate(); err != nil {
+		return "", nil, err
+	}
+
+	i := r.startBindingsAt
+	query := r.compileWith(&i) + `INSERT INTO ` + r.dialect.Quote(r.table)
+	if len(columns) > 0 {
+		query += ` (` + strings.Join(columns, `, `) + `)`
+	}
+
+	return query + ` ` + q.compileQuery(&i), append(r.withArgs(), q.queryArgs()...), nil
+}
+
+// placeholders returns n placeholders numbered from i
+func (r *builder) placeholders(n int, i *int) []string {
+	bindings := make([]string, n)
+	for k := range bindings {
+		bindings[k] = r.dialect.Placeholder(*i)
+		*i++
+	}
+
+	return bindings
+}
+
+// updateSQL builds UPDATE stmt for struct with where/from clauses
+func (r *builder) updateSQL(data any) (string, []any, error) {
+	if r.table == "" {
+		return "", nil, errTableCallBeforeOp
+	}
+
+	if err := r.validate(); err != nil {
+		return "", nil, err
+	}
+
+	_, values, _ := prepareBindingsForStruct(r.dialect, data)
+	i := r.startBindingsAt
+	query := r.compileUpdate(data, &i)
+
+	return query, append(append(r.withArgs(), values...), r.clauseArgs()...), nil
+}
+
+// compileUpdate constructs UPDATE stmt for struct with WITH, from and where clauses if any
+// numbering placeholders from i
+func (r *builder) compileUpdate(data any, i *int) string {
+	query := r.compileWith(i)
+	columns, values, _ := prepareBindingsForStruct(r.dialect, data)
+	bindings := r.placeholders(len(values), i)
+	setVal := ""
+	l := len(columns)
+	for k, col := range columns {
+		setVal += col + " = " + bindings[k]
+		if k < l-1 {
+			setVal += ", "
+		}
+	}
+
+	query += `UPDATE ` + r.dialect.Quote(r.table) + ` SET ` + setVal
+	if r.from != "" {
+		query += " FROM " + r.from
+	}
+
+	return query + r.compileClauses(i)
+}
+
+// deleteSQL builds DELETE stmt with where clause
+func (r *builder) deleteSQL() (string, []any, error) {
+	if r.table == "" {
+		return "", nil, errTableCallBeforeOp
+	}
+
+	if err := r.validate(); err != nil {
+		return "", nil, err
+	}
+
+	i := r.startBindingsAt
+
+	return r.compileDelete(&i), append(r.withArgs(), r.clauseArgs()...), nil
+}
+
+// compileDelete constructs DELETE stmt with WITH and where clauses if any numbering placeholders from i
+func (r *builder) compileDelete(i *int) string {
+	query := r.compileWith(i) + `DELETE FROM ` + r.dialect.Quote(r.table)
+
+	return query + r.compileClauses(i)
+}
+
+// replaceSQL builds INSERT stmt for struct updating conflicting row
+func (r *builder) replaceSQL(data any, conflict string) (string, []any, error) {
+	if r.table == "" {
+		return "", nil, errTableCallBeforeOp
+	}
+
+	if err := r.validate(); err != nil {
+		return "", nil, err
+	}
+
+	columns, values, _ := prepareBindingsForStruct(r.dialect, data)
+	i := r.startBindingsAt
+
+	return r.compileInsert(data, &i) + r.dialect.Upsert(conflict, columns), append(r.withArgs(), values...), nil
+}
+
+// existsSQL builds query checking whether conditional rows exist
+func (r *builder) existsSQL() (string, []any, error) {
+	if r.table == "" {
+		return "", nil, errTableCallBeforeOp
+	}
+
+	bldr := r.clone()
+	bldr.columns = []string{"1"}
+	bldr.selectSubs = nil
+	bldr.selectRaw = nil
+	if err := bldr.validate(); err != nil {
+		return "", nil, err
+	}
+
+	i := bldr.startBindingsAt
+	query := bldr.compileWith(&i) + `SELECT EXISTS(` + bldr.compileSelect(&i) + `)`
+
+	return query, append(bldr.withArgs(), bldr.args()...), nil
+}
+
+// aggregateSQL builds select of aggregate expression on the copy, so the query can be reused
+func (r *builder) aggregateSQL(expr string) (string, []any, error) {
+	if r.table == "" {
+		return "", nil, errTableCallBeforeOp
+	}
+
+	bldr := r.clone()
+	bldr.columns = []string{expr}
+	bldr.selectSubs = nil
+	bldr.selectRaw = nil
+	if err := bldr.validate(); err != nil {
+		return "", nil, err
+	}
+
+	i := bldr.startBindingsAt
+	query := bldr.compileWith(&i) + bldr.compileSelect(&i)
+
+	return query, append(bldr.withArgs(), bldr.args()...), nil
+}
+
+// incrDecrSQL builds UPDATE stmt incrementing or decrementing column depending on sign with where/from clauses
+func (r *builder) incrDecrSQL(column, sign string, on uint64) (string, []any, error) {
+	if r.table == "" {
+		return "", nil, errTableCallBeforeOp
+	}
+
+	if err := r.validate(); err != nil {
+		return "", nil, err
+	}
+
+	i := r.startBindingsAt
+	query := r.compileWith(&i) + `UPDATE ` + r.dialect.Quote(r.table) + ` SET ` + column + ` = ` + column + sign + strconv.FormatUint(on, 10)
+	if r.from != "" {
+		query += " FROM " + r.from
+	}
+
+	return query + r.compileClauses(&i), append(r.withArgs(), r.clauseArgs()...), nil
+}
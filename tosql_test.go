@@ -0,0 +1,92 @@
+package buildsqlx
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_ToSQL(t *testing.T) {
+	q := db.Table(UsersTable).Select("name", "points").Where("points", ">", 10).AndWhereIn("id", []int64{1, 2}).
+		OrderBy("name", "ASC").Limit(5)
+
+	query, args, err := q.ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT name, points FROM "test_users" WHERE points > $1 AND id IN ($2, $3) ORDER BY name ASC LIMIT 5`, query)
+	require.Equal(t, []any{10, int64(1), int64(2)}, args)
+
+	query, args, err = q.CountSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT COUNT(*) FROM "test_users" WHERE points > $1 AND id IN ($2, $3) ORDER BY name ASC LIMIT 5`, query)
+	require.Equal(t, []any{10, int64(1), int64(2)}, args)
+
+	query, _, err = q.SumSQL("points")
+	require.NoError(t, err)
+	require.Equal(t, `SELECT SUM(points) FROM "test_users" WHERE points > $1 AND id IN ($2, $3) ORDER BY name ASC LIMIT 5`, query)
+
+	// compiling doesn't change the query
+	query, _, err = q.ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT name, points FROM "test_users" WHERE points > $1 AND id IN ($2, $3) ORDER BY name ASC LIMIT 5`, query)
+
+	query, args, err = db.Table(UsersTable).Where("id", "=", 1).ExistsSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT EXISTS(SELECT 1 FROM "test_users" WHERE id = $1)`, query)
+	require.Equal(t, []any{1}, args)
+
+	query, args, err = db.Table(UsersTable).Select("name").Union().Table(PostsTable).Select("title").ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT name FROM "test_users" UNION SELECT title FROM "test_posts"`, query)
+	require.Empty(t, args)
+
+	_, _, err = db.Select("name").ToSQL()
+	require.Equal(t, errTableCallBeforeOp, err)
+}
+
+func TestDB_ToSQL_Statements(t *testing.T) {
+	user := User{ID: 1, Name: "Alex Shmidt", Points: 123}
+
+	query, args, err := db.Table(UsersTable).InsertSQL(user)
+	require.NoError(t, err)
+	require.Equal(t, `INSERT INTO "test_users" (id, name, points) VALUES($1, $2, $3)`, query)
+	require.Equal(t, []any{"1", "Alex Shmidt", "123"}, args)
+
+	query, _, err = db.Table(UsersTable).InsertGetIdSQL(user)
+	require.NoError(t, err)
+	require.Equal(t, `INSERT INTO "test_users" (id, name, points) VALUES($1, $2, $3) RETURNING id`, query)
+
+	query, args, err = db.Table(UsersTable).Where("id", "=", 1).UpdateSQL(user)
+	require.NoError(t, err)
+	require.Equal(t, `UPDATE "test_users" SET id = $1, name = $2, points = $3 WHERE id = $4`, query)
+	require.Equal(t, []any{"1", "Alex Shmidt", "123", 1}, args)
+
+	query, args, err = db.Table(UsersTable).Where("points", "<", 10).DeleteSQL()
+	require.NoError(t, err)
+	require.Equal(t, `DELETE FROM "test_users" WHERE points < $1`, query)
+	require.Equal(t, []any{10}, args)
+
+	query, _, err = db.Table(UsersTable).ReplaceSQL(user, "id")
+	require.NoError(t, err)
+	require.Equal(t, `INSERT INTO "test_users" (id, name, points) VALUES($1, $2, $3) ON CONFLICT(id) DO UPDATE SET id = excluded.id, name = excluded.name, points = excluded.points`, query)
+
+	query, args, err = db.Table(UsersTable).IncrementSQL("points", 3)
+	require.NoError(t, err)
+	require.Equal(t, `UPDATE "test_users" SET points = points+3`, query)
+	require.Empty(t, args)
+
+	query, args, err = db.Table(UsersTable).Where("id", "=", 1).OrWhereIn("name", []any{"Alex", "Darth"}).IncrementSQL("points", 3)
+	require.NoError(t, err)
+	require.Equal(t, `UPDATE "test_users" SET points = points+3 WHERE id = $1 OR name IN ($2, $3)`, query)
+	require.Equal(t, []any{1, "Alex", "Darth"}, args)
+
+	mysqlDb := NewDb(NewConnectionFromDb(&sql.DB{}), WithDialect(MySQL{}))
+	query, _, err = mysqlDb.Table(UsersTable).InsertGetIdSQL(user)
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO `test_users` (id, name, points) VALUES(?, ?, ?)", query)
+
+	query, args, err = mysqlDb.Table(UsersTable).Where("id", "=", 1).DecrementSQL("points", 2)
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE `test_users` SET points = points-2 WHERE id = ?", query)
+	require.Equal(t, []any{1}, args)
+}
@@ -0,0 +1,205 @@
+package buildsqlx
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Attribute is a key-value pair describing Span, keys follow OpenTelemetry semantic conventions e.g.: db.statement
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Span is a unit of work started by Tracer, its method set is a subset of OpenTelemetry trace.Span,
+// so it can be backed by OpenTelemetry span with a thin adapter
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Tracer starts spans, context returned by Start carries the span to become a parent of spans started with it
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Attribute keys set on spans
+const (
+	AttrDBSystem     = "db.system"
+	AttrDBStatement  = "db.statement"
+	AttrDBOperation  = "db.operation"
+	AttrDBTable      = "db.sql.table"
+	AttrRowsAffected = "db.rows_affected"
+)
+
+// Tracing is a Hook opening span per statement and a parent span around InTransaction
+type Tracing struct {
+	tracer Tracer
+}
+
+// spanCtxKey keeps span started by the particular Tracing hook
+type spanCtxKey struct {
+	t *Tracing
+}
+
+// NewTracing returns Tracing hook starting spans with tracer
+func NewTracing(tracer Tracer) *Tracing {
+	return &Tracing{tracer: tracer}
+}
+
+// WithTracer adds Tracing hook to DB
+func WithTracer(tracer Tracer) Option {
+	return WithHooks(NewTracing(tracer))
+}
+
+// BeforeQuery starts statement span as a child of span in ctx if any
+func (t *Tracing) BeforeQuery(ctx context.Context, e *QueryEvent) context.Context {
+	name := string(e.Op)
+	if e.Table != "" {
+		name += " " + e.Table
+	}
+
+	ctx, span := t.tracer.Start(ctx, name,
+		Attribute{Key: AttrDBSystem, Value: dbSystem(e.Dialect)},
+		Attribute{Key: AttrDBStatement, Value: e.SQL},
+		Attribute{Key: AttrDBOperation, Value: string(e.Op)},
+		Attribute{Key: AttrDBTable, Value: e.Table},
+	)
+
+	return context.WithValue(ctx, spanCtxKey{t}, span)
+}
+
+// AfterQuery ends statement span
+func (t *Tracing) AfterQuery(ctx context.Context, e *QueryEvent) {
+	span, ok := ctx.Value(spanCtxKey{t}).(Span)
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(Attribute{Key: AttrRowsAffected, Value: e.RowsAffected})
+	if e.Err != nil {
+		span.RecordError(e.Err)
+	}
+	span.End()
+}
+
+// BeforeTx starts transaction span
+func (t *Tracing) BeforeTx(ctx context.Context, e *TxEvent) context.Context {
+	ctx, span := t.tracer.Start(ctx, "transaction", Attribute{Key: AttrDBSystem, Value: dbSystem(e.Dialect)})
+
+	return context.WithValue(ctx, spanCtxKey{t}, span)
+}
+
+// AfterTx ends transaction span
+func (t *Tracing) AfterTx(ctx context.Context, e *TxEvent) {
+	span, ok := ctx.Value(spanCtxKey{t}).(Span)
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(
+		Attribute{Key: "db.transaction.committed", Value: e.Committed},
+		Attribute{Key: "db.transaction.attempt", Value: e.Attempt},
+	)
+	if e.Err != nil {
+		span.RecordError(e.Err)
+	}
+	span.End()
+}
+
+// dbSystem maps dialect name to OpenTelemetry db.system value
+func dbSystem(dialect string) string {
+	if dialect == "postgres" {
+		return "postgresql"
+	}
+
+	return dialect
+}
+
+// SpanRecorder is an in-memory Tracer recording ended spans e.g. to test tracing offline
+type SpanRecorder struct {
+	mu    sync.Mutex
+	id    uint64
+	spans []*RecordedSpan
+}
+
+// RecordedSpan is a span recorded by SpanRecorder
+type RecordedSpan struct {
+	ID uint64
+	// ParentID is 0 for root spans
+	ParentID   uint64
+	Name       string
+	Attributes map[string]any
+	Errors     []error
+	StartedAt  time.Time
+	EndedAt    time.Time
+
+	recorder *SpanRecorder
+}
+
+type recordedSpanCtxKey struct{}
+
+// NewSpanRecorder returns an empty SpanRecorder
+func NewSpanRecorder() *SpanRecorder {
+	return &SpanRecorder{}
+}
+
+// Start starts span as a child of SpanRecorder span in ctx if any
+func (r *SpanRecorder) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	r.mu.Lock()
+	r.id++
+	span := &RecordedSpan{ID: r.id, Name: name, Attributes: make(map[string]any), StartedAt: time.Now(), recorder: r}
+	r.mu.Unlock()
+
+	if parent, ok := ctx.Value(recordedSpanCtxKey{}).(*RecordedSpan); ok {
+		span.ParentID = parent.ID
+	}
+	span.SetAttributes(attrs...)
+
+	return context.WithValue(ctx, recordedSpanCtxKey{}, span), span
+}
+
+// Spans returns ended spans in order they were ended
+func (r *SpanRecorder) Spans() []*RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*RecordedSpan(nil), r.spans...)
+}
+
+// Reset removes recorded spans
+func (r *SpanRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.spans = nil
+}
+
+// SetAttributes sets attributes overwriting the existing ones with the same keys
+func (s *RecordedSpan) SetAttributes(attrs ...Attribute) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	for _, attr := range attrs {
+		s.Attributes[attr.Key] = attr.Value
+	}
+}
+
+// RecordError records err
+func (s *RecordedSpan) RecordError(err error) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	s.Errors = append(s.Errors, err)
+}
+
+// End ends span adding it to recorded ones
+func (s *RecordedSpan) End() {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	s.EndedAt = time.Now()
+	s.recorder.spans = append(s.recorder.spans, s)
+}
@@ -0,0 +1,77 @@
+package buildsqlx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_Tracing(t *testing.T) {
+	rec := NewSpanRecorder()
+	tdb := NewDb(NewConnection("postgres", dbConnInfo), WithTracer(rec))
+
+	_, err := tdb.Truncate(TestTable)
+	require.NoError(t, err)
+
+	ctx, root := rec.Start(context.Background(), "request")
+	err = tdb.Table(TestTable).InsertCtx(ctx, data)
+	require.NoError(t, err)
+
+	err = tdb.InTransactionCtx(ctx, func() (any, error) {
+		return tdb.Table(TestTable).Where("foo", "=", data.Foo).DeleteCtx(ctx)
+	})
+	require.NoError(t, err)
+	root.End()
+
+	spans := rec.Spans()
+	require.Len(t, spans, 5)
+	truncate, insert, del, tx, req := spans[0], spans[1], spans[2], spans[3], spans[4]
+	require.Equal(t, uint64(0), truncate.ParentID)
+	require.Equal(t, "request", req.Name)
+
+	require.Equal(t, "insert test", insert.Name)
+	require.Equal(t, req.ID, insert.ParentID)
+	require.Equal(t, "postgresql", insert.Attributes[AttrDBSystem])
+	require.Equal(t, `INSERT INTO "test" (foo, bar, baz) VALUES($1, $2, $3)`, insert.Attributes[AttrDBStatement])
+	require.Equal(t, TestTable, insert.Attributes[AttrDBTable])
+	require.Equal(t, int64(1), insert.Attributes[AttrRowsAffected])
+
+	require.Equal(t, "transaction", tx.Name)
+	require.Equal(t, req.ID, tx.ParentID)
+	require.Equal(t, true, tx.Attributes["db.transaction.committed"])
+	require.Equal(t, "delete test", del.Name)
+	require.Equal(t, tx.ID, del.ParentID)
+}
+
+func TestTracing_Errors(t *testing.T) {
+	rec := NewSpanRecorder()
+	tr := NewTracing(rec)
+	hooks := []Hook{tr}
+
+	txEvent := &TxEvent{Dialect: "mysql"}
+	txCtx := beforeTx(context.Background(), hooks, txEvent)
+
+	txn := &Txn{ctx: txCtx}
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &QueryEvent{Op: OpUpdate, Table: UsersTable, Dialect: "mysql"}
+	stmtCtx := beforeQuery(txn.hookCtx(ctx), hooks, e)
+	cancel()
+	// statement context is cancelled by ctx passed to statement, while carrying transaction span
+	require.Error(t, stmtCtx.Err())
+	afterQuery(stmtCtx, hooks, e, errors.New("some err"))
+	afterTx(txCtx, hooks, txEvent, errors.New("some err"))
+
+	spans := rec.Spans()
+	require.Len(t, spans, 2)
+	require.Equal(t, "update test_users", spans[0].Name)
+	require.Equal(t, "mysql", spans[0].Attributes[AttrDBSystem])
+	require.Equal(t, spans[1].ID, spans[0].ParentID)
+	require.EqualError(t, spans[0].Errors[0], "some err")
+	require.Equal(t, false, spans[1].Attributes["db.transaction.committed"])
+	require.Len(t, spans[1].Errors, 1)
+
+	rec.Reset()
+	require.Empty(t, rec.Spans())
+}
@@ -0,0 +1,355 @@
+package buildsqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+var errTxAlreadyStarted = fmt.Errorf("sql: transaction has already been started")
+
+// TxOptions configures transaction started by BeginTx, InTransactionOpts or InTransactionRetry
+type TxOptions struct {
+	// Isolation is the transaction isolation level, sql.LevelDefault means the driver default
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+	// Deferrable makes SERIALIZABLE READ ONLY transaction wait for a snapshot that can't fail with serialization
+	// error e.g. for consistent reporting, PostgreSQL only
+	Deferrable bool
+	// Settings are applied for the transaction only at its start e.g.: {"statement_timeout": "5s", "lock_timeout": "1s"}
+	Settings map[string]string
+}
+
+// Begin starts transaction returning DB on which every query runs in the transaction until Commit or Rollback
+func (r *DB) Begin() (*DB, error) {
+	return r.BeginCtx(context.Background())
+}
+
+// BeginCtx starts transaction with the given context returning DB on which every query runs in the transaction
+// until Commit or Rollback, the driver rolls back the transaction if the context is done before Commit
+func (r *DB) BeginCtx(ctx context.Context) (*DB, error) {
+	return r.BeginTx(ctx, nil)
+}
+
+// BeginTx starts transaction with the given context and options returning DB on which every query runs in the
+// transaction until Commit or Rollback, nil options mean driver defaults
+func (r *DB) BeginTx(ctx context.Context, opts *TxOptions) (*DB, error) {
+	if r.Txn != nil {
+		return nil, errTxAlreadyStarted
+	}
+
+	hooks := r.allHooks()
+	e := &TxEvent{Dialect: r.Builder.dialect.Name(), Attempt: 1}
+	txCtx := beforeTx(ctx, hooks, e)
+
+	tx, err := r.beginTx(ctx, txCtx, hooks, opts)
+	if err != nil {
+		afterTx(txCtx, hooks, e, err)
+		return nil, err
+	}
+
+	b := newBuilder()
+	b.dialect = r.Builder.dialect
+
+	return &DB{
+		Builder: b,
+		Conn:    r.Conn,
+		Txn:     &Txn{Tx: tx, Builder: b, hooks: hooks, ctx: txCtx, txEvent: e},
+		hooks:   r.hooks,
+	}, nil
+}
+
+// beginTx starts transaction with options making it deferrable and applying settings if needed,
+// the transaction is rolled back if any of those fails
+func (r *DB) beginTx(ctx, txCtx context.Context, hooks []Hook, opts *TxOptions) (*sql.Tx, error) {
+	if opts == nil {
+		return r.Sql().BeginTx(ctx, nil)
+	}
+
+	d := r.Builder.dialect
+	var stmts []*QueryEvent
+	if opts.Deferrable {
+		if d.Deferrable() == "" {
+			return nil, fmt.Errorf("sql: %s dialect doesn't support deferrable transactions", d.Name())
+		}
+
+		stmts = append(stmts, r.Builder.event(OpTx, "", d.Deferrable(), nil))
+	}
+
+	names := make([]string, 0, len(opts.Settings))
+	for name := range opts.Settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		query, args := d.SetLocal(name, opts.Settings[name])
+		if query == "" {
+			return nil, fmt.Errorf("sql: %s dialect doesn't support transaction settings e.g.: '%s'", d.Name(), name)
+		}
+
+		stmts = append(stmts, r.Builder.event(OpTx, "", query, args))
+	}
+
+	tx, err := r.Sql().BeginTx(ctx, &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range stmts {
+		e.InTx = true
+		if _, err = execHooked(txCtx, tx, hooks, e); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	return tx, nil
+}
+
+// Commit commits transaction started by Begin
+func (r *DB) Commit() error {
+	if r.Txn == nil {
+		return errTransactionModeWithoutTx
+	}
+
+	if err := r.Txn.Commit(); err != nil {
+		return err
+	}
+
+	r.Conn.wrote(r.Txn.ctx)
+	return nil
+}
+
+// Rollback rolls back transaction started by Begin
+func (r *DB) Rollback() error {
+	if r.Txn == nil {
+		return errTransactionModeWithoutTx
+	}
+
+	return r.Txn.Rollback()
+}
+
+// Commit commits transaction
+func (r *Txn) Commit() error {
+	if r.Tx == nil {
+		return errTransactionModeWithoutTx
+	}
+
+	err := r.Tx.Commit()
+	if r.txEvent != nil {
+		r.txEvent.Committed = err == nil
+	}
+	r.finish(err)
+
+	if err != nil {
+		if !errors.Is(err, sql.ErrTxDone) { // the failed commit rolls transaction back
+			runCallbacks(r.afterRollback)
+			r.afterCommit, r.afterRollback = nil, nil
+		}
+
+		return err
+	}
+
+	runCallbacks(r.afterCommit)
+	r.afterCommit, r.afterRollback = nil, nil
+
+	return nil
+}
+
+// Rollback rolls back transaction
+func (r *Txn) Rollback() error {
+	if r.Tx == nil {
+		return errTransactionModeWithoutTx
+	}
+
+	err := r.Tx.Rollback()
+	r.finish(err)
+
+	if !errors.Is(err, sql.ErrTxDone) {
+		runCallbacks(r.afterRollback)
+		r.afterCommit, r.afterRollback = nil, nil
+	}
+
+	return err
+}
+
+// AfterCommit registers fn to be called after the current transaction is committed e.g. to invalidate cache,
+// fn registered within a savepoint is dropped if the savepoint is rolled back,
+// fn is called immediately out of transaction mode
+func (r *DB) AfterCommit(fn func()) {
+	if r.Txn == nil {
+		fn()
+		return
+	}
+
+	r.Txn.afterCommit = append(r.Txn.afterCommit, fn)
+}
+
+// AfterRollback registers fn to be called after the current transaction is rolled back,
+// fn registered within a savepoint is called when the savepoint is rolled back,
+// fn is never called out of transaction mode
+func (r *DB) AfterRollback(fn func()) {
+	if r.Txn != nil {
+		r.Txn.afterRollback = append(r.Txn.afterRollback, fn)
+	}
+}
+
+// runCallbacks calls callbacks in order they were registered
+func runCallbacks(callbacks []func()) {
+	for _, fn := range callbacks {
+		fn()
+	}
+}
+
+// finish reports the end of transaction to hooks once
+func (r *Txn) finish(err error) {
+	if r.txEvent == nil {
+		return
+	}
+
+	afterTx(r.ctx, r.hooks, r.txEvent, err)
+	r.txEvent = nil
+}
+
+// txn returns Txn bound to the current builder of DB
+func (r *DB) txn() *Txn {
+	txn := *r.Txn
+	txn.Builder = r.Builder
+
+	return &txn
+}
+
+// Savepoint creates savepoint with name in the current transaction
+func (r *DB) Savepoint(name string) error {
+	return r.SavepointCtx(context.Background(), name)
+}
+
+// SavepointCtx creates savepoint with name in the current transaction with the given context
+func (r *DB) SavepointCtx(ctx context.Context, name string) error {
+	return r.txControl(ctx, "SAVEPOINT "+r.Builder.dialect.Quote(name))
+}
+
+// RollbackTo rolls back changes made after savepoint with name was created, the savepoint is kept
+func (r *DB) RollbackTo(name string) error {
+	return r.RollbackToCtx(context.Background(), name)
+}
+
+// RollbackToCtx rolls back changes made after savepoint with name was created with the given context,
+// the savepoint is kept
+func (r *DB) RollbackToCtx(ctx context.Context, name string) error {
+	return r.txControl(ctx, "ROLLBACK TO SAVEPOINT "+r.Builder.dialect.Quote(name))
+}
+
+// Release destroys savepoint with name keeping changes made after it was created
+func (r *DB) Release(name string) error {
+	return r.ReleaseCtx(context.Background(), name)
+}
+
+// ReleaseCtx destroys savepoint with name keeping changes made after it was created with the given context
+func (r *DB) ReleaseCtx(ctx context.Context, name string) error {
+	return r.txControl(ctx, "RELEASE SAVEPOINT "+r.Builder.dialect.Quote(name))
+}
+
+// txControl runs transaction control stmt in the current transaction
+func (r *DB) txControl(ctx context.Context, query string) error {
+	if r.Txn == nil {
+		return errTransactionModeWithoutTx
+	}
+
+	_, err := r.exec(ctx, OpTx, "", query)
+
+	return err
+}
+
+// runSavepoint runs fn passed to nested InTransaction within sp_n savepoint,
+// rolling back to it on error or if result means rollback and releasing it otherwise
+func (r *DB) runSavepoint(ctx context.Context, fn func() (any, error)) error {
+	return r.inSavepoint(ctx, func() (bool, error) {
+		res, err := fn()
+		return committable(res), err
+	})
+}
+
+// inSavepoint runs fn within sp_n savepoint, rolling back to it if fn fails or returns false and releasing it otherwise,
+// callbacks registered by fn are dropped or called on rollback to savepoint
+func (r *DB) inSavepoint(ctx context.Context, fn func() (bool, error)) error {
+	txn := r.Txn
+	txn.depth++
+	defer func() {
+		txn.depth--
+	}()
+
+	name := "sp_" + strconv.Itoa(txn.depth)
+	if err := r.SavepointCtx(ctx, name); err != nil {
+		return err
+	}
+
+	commits, rollbacks := len(txn.afterCommit), len(txn.afterRollback)
+	rollbackTo := func() error {
+		if err := r.RollbackToCtx(ctx, name); err != nil {
+			return err
+		}
+
+		runCallbacks(txn.afterRollback[rollbacks:])
+		txn.afterCommit, txn.afterRollback = txn.afterCommit[:commits], txn.afterRollback[:rollbacks]
+
+		return nil
+	}
+
+	ok, err := fn()
+	if err != nil {
+		if errSp := rollbackTo(); errSp != nil {
+			return errSp
+		}
+
+		return err
+	}
+
+	if !ok {
+		return rollbackTo()
+	}
+
+	return r.ReleaseCtx(ctx, name)
+}
+
+// InTx runs fn in transaction committing it if fn returns nil error and rolling back otherwise, the transaction is
+// also rolled back if fn panics, being called in transaction mode it runs fn within a savepoint of db transaction
+func InTx[T any](ctx context.Context, db *DB, fn func(tx *DB) (T, error)) (T, error) {
+	return InTxOpts(ctx, db, nil, fn)
+}
+
+// InTxOpts runs fn as InTx does starting transaction with options
+func InTxOpts[T any](ctx context.Context, db *DB, opts *TxOptions, fn func(tx *DB) (T, error)) (res T, err error) {
+	if db.Txn != nil {
+		err = db.inSavepoint(ctx, func() (bool, error) {
+			res, err = fn(db)
+			return true, err
+		})
+
+		return res, err
+	}
+
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return res, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	res, err = fn(tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return res, err
+	}
+
+	return res, tx.Commit()
+}
@@ -0,0 +1,286 @@
+package buildsqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_Begin(t *testing.T) {
+	_, err := db.Truncate(TestTable)
+	require.NoError(t, err)
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	err = tx.Table(TestTable).Insert(data)
+	require.NoError(t, err)
+
+	err = tx.Table(TestTable).InsertBatch(batchDataStruct)
+	require.NoError(t, err)
+
+	// reads see uncommitted rows of the transaction
+	cnt, err := tx.Table(TestTable).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(len(batchDataStruct)+1), cnt)
+
+	ds := &DataStruct{}
+	err = tx.Table(TestTable).Select("foo", "bar", "baz").Where("foo", "=", data.Foo).ScanStruct(ds)
+	require.NoError(t, err)
+	require.Equal(t, data.Bar, ds.Bar)
+
+	_, err = tx.Table(TestTable).Increment("baz", 1)
+	require.NoError(t, err)
+
+	// while rows aren't visible out of the transaction
+	cnt, err = db.Table(TestTable).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), cnt)
+
+	require.NoError(t, tx.Rollback())
+	_, err = tx.Table(TestTable).Count()
+	require.Error(t, err)
+
+	tx, err = db.Begin()
+	require.NoError(t, err)
+
+	err = tx.Table(TestTable).Insert(data)
+	require.NoError(t, err)
+
+	_, err = tx.Begin()
+	require.EqualError(t, err, "sql: transaction has already been started")
+
+	require.NoError(t, tx.Commit())
+	require.Error(t, tx.Commit())
+
+	cnt, err = db.Table(TestTable).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), cnt)
+
+	_, err = db.Truncate(TestTable)
+	require.NoError(t, err)
+}
+
+func TestDB_CommitWithoutTx(t *testing.T) {
+	require.Equal(t, errTransactionModeWithoutTx, db.Commit())
+	require.Equal(t, errTransactionModeWithoutTx, db.Rollback())
+}
+
+func TestDB_NestedTransactions(t *testing.T) {
+	_, err := db.Truncate(TestTable)
+	require.NoError(t, err)
+
+	insert := func(foo string) (any, error) {
+		return db.Table(TestTable).InsertGetId(DataStruct{Foo: foo, Bar: "bar"})
+	}
+
+	err = db.InTransaction(func() (any, error) {
+		if _, err := insert("outer"); err != nil {
+			return nil, err
+		}
+
+		// inner failure rolls back to savepoint only
+		errInner := db.InTransaction(func() (any, error) {
+			if _, err := insert("inner 1"); err != nil {
+				return nil, err
+			}
+
+			return nil, errors.New("some err")
+		})
+		require.EqualError(t, errInner, "some err")
+
+		errInner = db.InTransaction(func() (any, error) {
+			return insert("inner 2")
+		})
+		require.NoError(t, errInner)
+
+		return db.Table(TestTable).Count()
+	})
+	require.NoError(t, err)
+
+	cnt, err := db.Table(TestTable).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), cnt)
+
+	for foo, exists := range map[string]bool{"outer": true, "inner 1": false, "inner 2": true} {
+		is, err := db.Table(TestTable).Where("foo", "=", foo).Exists()
+		require.NoError(t, err)
+		require.Equal(t, exists, is, foo)
+	}
+
+	_, err = db.Truncate(TestTable)
+	require.NoError(t, err)
+}
+
+func TestDB_Savepoint(t *testing.T) {
+	_, err := db.Truncate(TestTable)
+	require.NoError(t, err)
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	err = tx.Table(TestTable).Insert(data)
+	require.NoError(t, err)
+
+	require.NoError(t, tx.Savepoint("before_delete"))
+	_, err = tx.Table(TestTable).Delete()
+	require.NoError(t, err)
+	require.NoError(t, tx.RollbackTo("before_delete"))
+	require.NoError(t, tx.Release("before_delete"))
+	require.Error(t, tx.RollbackTo("before_delete"))
+	require.NoError(t, tx.Rollback())
+
+	require.Equal(t, errTransactionModeWithoutTx, db.Savepoint("sp"))
+}
+
+func TestDB_BeginTx(t *testing.T) {
+	tx, err := db.BeginTx(context.Background(), &TxOptions{
+		Isolation:  sql.LevelSerializable,
+		ReadOnly:   true,
+		Deferrable: true,
+		Settings:   map[string]string{"statement_timeout": "5s", "lock_timeout": "1s"},
+	})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	for setting, expected := range map[string]string{
+		"transaction_isolation":  "serializable",
+		"transaction_read_only":  "on",
+		"transaction_deferrable": "on",
+		"statement_timeout":      "5s",
+		"lock_timeout":           "1s",
+	} {
+		var val string
+		err = tx.Txn.Tx.QueryRow("SHOW " + setting).Scan(&val)
+		require.NoError(t, err)
+		require.Equal(t, expected, val, setting)
+	}
+
+	err = tx.Table(TestTable).Insert(data)
+	require.Error(t, err) // read-only transaction
+
+	// settings are local to transaction
+	err = db.InTransactionOpts(context.Background(), &TxOptions{Isolation: sql.LevelRepeatableRead}, func() (any, error) {
+		var val string
+		err := db.Txn.Tx.QueryRow("SHOW statement_timeout").Scan(&val)
+		require.NoError(t, err)
+		require.Equal(t, "0", val)
+
+		return db.Table(TestTable).Count()
+	})
+	require.NoError(t, err)
+}
+
+func TestDB_BeginTx_Unsupported(t *testing.T) {
+	mysqlDb := NewDb(NewConnectionFromDb(&sql.DB{}), WithDialect(MySQL{}))
+
+	_, err := mysqlDb.BeginTx(context.Background(), &TxOptions{Deferrable: true})
+	require.EqualError(t, err, "sql: mysql dialect doesn't support deferrable transactions")
+
+	_, err = mysqlDb.BeginTx(context.Background(), &TxOptions{Settings: map[string]string{"lock_timeout": "1s"}})
+	require.EqualError(t, err, "sql: mysql dialect doesn't support transaction settings e.g.: 'lock_timeout'")
+}
+
+func TestInTx(t *testing.T) {
+	_, err := db.Truncate(TestTable)
+	require.NoError(t, err)
+
+	var events []string
+	ctx := context.Background()
+	// struct result with nil error commits
+	ds, err := InTx(ctx, db, func(tx *DB) (DataStruct, error) {
+		tx.AfterCommit(func() {
+			events = append(events, "commit")
+		})
+		tx.AfterRollback(func() {
+			events = append(events, "rollback")
+		})
+
+		_, err := InTx(ctx, tx, func(tx *DB) (bool, error) {
+			tx.AfterCommit(func() {
+				events = append(events, "inner commit")
+			})
+			tx.AfterRollback(func() {
+				events = append(events, "inner rollback")
+			})
+
+			return false, tx.Table(TestTable).Insert(DataStruct{Foo: "inner"})
+		})
+		require.NoError(t, err)
+
+		_, err = InTx(ctx, tx, func(tx *DB) (bool, error) {
+			tx.AfterCommit(func() {
+				events = append(events, "failed inner commit")
+			})
+
+			return false, errors.New("some err")
+		})
+		require.EqualError(t, err, "some err")
+
+		err = tx.Table(TestTable).Insert(data)
+		if err != nil {
+			return DataStruct{}, err
+		}
+
+		res := DataStruct{}
+		err = tx.Table(TestTable).Select("foo", "bar").Where("foo", "=", data.Foo).ScanStruct(&res)
+		return res, err
+	})
+	require.NoError(t, err)
+	require.Equal(t, data.Bar, ds.Bar)
+	// callbacks of released savepoint are called on commit, while the ones of rolled back savepoint are dropped
+	require.Equal(t, []string{"commit", "inner commit"}, events)
+
+	cnt, err := db.Table(TestTable).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), cnt)
+
+	// error rolls back
+	events = nil
+	_, err = InTx(ctx, db, func(tx *DB) (int64, error) {
+		tx.AfterRollback(func() {
+			events = append(events, "rollback")
+		})
+
+		_, err := tx.Table(TestTable).Delete()
+		require.NoError(t, err)
+
+		return 0, errors.New("some err")
+	})
+	require.EqualError(t, err, "some err")
+	require.Equal(t, []string{"rollback"}, events)
+
+	// nil error commits despite of zero result
+	_, err = InTx(ctx, db, func(tx *DB) (int64, error) {
+		_, err := tx.Table(TestTable).Delete()
+		return 0, err
+	})
+	require.NoError(t, err)
+
+	// panic rolls back
+	require.Panics(t, func() {
+		_, _ = InTx(ctx, db, func(tx *DB) (any, error) {
+			require.NoError(t, tx.Table(TestTable).Insert(data))
+			panic("some panic")
+		})
+	})
+
+	cnt, err = db.Table(TestTable).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), cnt)
+}
+
+func TestDB_AfterCommitWithoutTx(t *testing.T) {
+	called := false
+	db.AfterCommit(func() {
+		called = true
+	})
+	require.True(t, called)
+
+	db.AfterRollback(func() {
+		t.Fatal("must not be called")
+	})
+}
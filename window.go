@@ -0,0 +1,175 @@
+package buildsqlx
+
+import (
+	"strconv"
+	"strings"
+)
+
+// frame boundaries of Window Rows, Range and Groups
+const (
+	UnboundedPreceding = "UNBOUNDED PRECEDING"
+	UnboundedFollowing = "UNBOUNDED FOLLOWING"
+	CurrentRow         = "CURRENT ROW"
+)
+
+// Preceding returns frame boundary of n rows (or values for Range) before the current row
+func Preceding(n int64) string {
+	return strconv.FormatInt(n, 10) + " PRECEDING"
+}
+
+// Following returns frame boundary of n rows (or values for Range) after the current row
+func Following(n int64) string {
+	return strconv.FormatInt(n, 10) + " FOLLOWING"
+}
+
+// Window is a window definition of OVER and WINDOW clauses
+type Window struct {
+	partition []string
+	orderBy   []string
+	frame     string
+}
+
+// NewWindow constructs an empty window definition of all the rows
+func NewWindow() *Window {
+	return &Window{}
+}
+
+// PartitionBy adds columns rows are grouped by to the window
+func (w *Window) PartitionBy(columns ...string) *Window {
+	w.partition = append(w.partition, columns...)
+	return w
+}
+
+// OrderBy adds ORDER BY expression of the window
+func (w *Window) OrderBy(column, direction string) *Window {
+	w.orderBy = append(w.orderBy, column+" "+direction)
+	return w
+}
+
+// Rows sets frame of rows between start and end boundaries e.g. Rows(UnboundedPreceding, CurrentRow)
+func (w *Window) Rows(start, end string) *Window {
+	return w.buildFrame("ROWS", start, end)
+}
+
+// Range sets frame of rows between start and end boundaries by values of the ordering column
+func (w *Window) Range(start, end string) *Window {
+	return w.buildFrame("RANGE", start, end)
+}
+
+// Groups sets frame of peer groups between start and end boundaries
+func (w *Window) Groups(start, end string) *Window {
+	return w.buildFrame("GROUPS", start, end)
+}
+
+func (w *Window) buildFrame(mode, start, end string) *Window {
+	w.frame = mode + " BETWEEN " + start + " AND " + end
+	return w
+}
+
+// String returns window definition without parentheses e.g. PARTITION BY user_id ORDER BY id ASC
+func (w *Window) String() string {
+	var clauses []string
+	if len(w.partition) > 0 {
+		clauses = append(clauses, "PARTITION BY "+strings.Join(w.partition, ", "))
+	}
+
+	if len(w.orderBy) > 0 {
+		clauses = append(clauses, "ORDER BY "+strings.Join(w.orderBy, ", "))
+	}
+
+	if w.frame != "" {
+		clauses = append(clauses, w.frame)
+	}
+
+	return strings.Join(clauses, " ")
+}
+
+// WindowExpr is a window function call to be selected by Select/AddSelect, ex.:
+// AddSelect(RowNumber().Over(NewWindow().PartitionBy("user_id").OrderBy("id", "DESC")).As("rn"))
+type WindowExpr struct {
+	fn   string
+	over string
+}
+
+// WindowFn constructs call of any window or aggregate function over window e.g. WindowFn("SUM(points)")
+func WindowFn(fn string) *WindowExpr {
+	return &WindowExpr{fn: fn}
+}
+
+// RowNumber constructs ROW_NUMBER() call numbering rows of the partition from 1
+func RowNumber() *WindowExpr {
+	return WindowFn("ROW_NUMBER()")
+}
+
+// Rank constructs RANK() call ranking rows of the partition with gaps
+func Rank() *WindowExpr {
+	return WindowFn("RANK()")
+}
+
+// DenseRank constructs DENSE_RANK() call ranking rows of the partition without gaps
+func DenseRank() *WindowExpr {
+	return WindowFn("DENSE_RANK()")
+}
+
+// Ntile constructs NTILE(n) call dividing rows of the partition into n buckets
+func Ntile(n int64) *WindowExpr {
+	return WindowFn("NTILE(" + strconv.FormatInt(n, 10) + ")")
+}
+
+// Lag constructs LAG(column, offset) call getting the value of row offset rows before the current one
+func Lag(column string, offset int64) *WindowExpr {
+	return WindowFn("LAG(" + column + ", " + strconv.FormatInt(offset, 10) + ")")
+}
+
+// Lead constructs LEAD(column, offset) call getting the value of row offset rows after the current one
+func Lead(column string, offset int64) *WindowExpr {
+	return WindowFn("LEAD(" + column + ", " + strconv.FormatInt(offset, 10) + ")")
+}
+
+// FirstValue constructs FIRST_VALUE(column) call getting the value of the first row of the frame
+func FirstValue(column string) *WindowExpr {
+	return WindowFn("FIRST_VALUE(" + column + ")")
+}
+
+// LastValue constructs LAST_VALUE(column) call getting the value of the last row of the frame
+func LastValue(column string) *WindowExpr {
+	return WindowFn("LAST_VALUE(" + column + ")")
+}
+
+// Over sets window definition the function is called over
+func (e *WindowExpr) Over(w *Window) *WindowExpr {
+	e.over = "(" + w.String() + ")"
+	return e
+}
+
+// OverWindow sets window defined by DB.Window with name the function is called over
+func (e *WindowExpr) OverWindow(name string) *WindowExpr {
+	e.over = name
+	return e
+}
+
+// As returns the call aliased as column, so it can be scanned into the struct field with the same name or db tag
+func (e *WindowExpr) As(alias string) string {
+	return e.String() + " AS " + alias
+}
+
+// String returns the call e.g. ROW_NUMBER() OVER (PARTITION BY user_id)
+func (e *WindowExpr) String() string {
+	if e.over == "" {
+		return e.fn + " OVER ()"
+	}
+
+	return e.fn + " OVER " + e.over
+}
+
+// namedWindow is a window definition of WINDOW clause
+type namedWindow struct {
+	name   string
+	window string
+}
+
+// Window adds definition w of window name to WINDOW clause, so the functions can be called over it by OverWindow(name)
+func (r *DB) Window(name string, w *Window) *DB {
+	r.Builder.windows = append(r.Builder.windows, namedWindow{name: name, window: w.String()})
+	return r
+}
@@ -0,0 +1,85 @@
+package buildsqlx
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindow(t *testing.T) {
+	require.Equal(t, "ROW_NUMBER() OVER ()", RowNumber().String())
+	require.Equal(t, "RANK() OVER (ORDER BY points DESC)", Rank().Over(NewWindow().OrderBy("points", "DESC")).String())
+	require.Equal(t, "DENSE_RANK() OVER (PARTITION BY user_id, title) AS dr",
+		DenseRank().Over(NewWindow().PartitionBy("user_id", "title")).As("dr"))
+	require.Equal(t, "NTILE(4) OVER w", Ntile(4).OverWindow("w").String())
+	require.Equal(t, "FIRST_VALUE(name) OVER (ORDER BY id ASC RANGE BETWEEN 2 PRECEDING AND 1 FOLLOWING)",
+		FirstValue("name").Over(NewWindow().OrderBy("id", "ASC").Range(Preceding(2), Following(1))).String())
+	require.Equal(t, "LAST_VALUE(name) OVER (ORDER BY id ASC GROUPS BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING)",
+		LastValue("name").Over(NewWindow().OrderBy("id", "ASC").Groups(CurrentRow, UnboundedFollowing)).String())
+}
+
+func TestDB_Window(t *testing.T) {
+	w := NewWindow().PartitionBy("user_id").OrderBy("id", "ASC")
+	q := db.Table(PostsTable).Select("id", "title").AddSelect(
+		RowNumber().Over(NewWindow().PartitionBy("user_id").OrderBy("id", "DESC")).As("rn"),
+		WindowFn("COUNT(*)").Over(NewWindow().OrderBy("id", "ASC").Rows(UnboundedPreceding, CurrentRow)).As("total"),
+		Lag("title", 1).OverWindow("w").As("prev"),
+	).Window("w", w).Where("user_id", ">", 0).OrderBy("id", "ASC")
+	// changing the definition doesn't affect the query
+	w.Rows(UnboundedPreceding, CurrentRow)
+
+	query, args, err := q.ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT id, title, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY id DESC) AS rn, `+
+		`COUNT(*) OVER (ORDER BY id ASC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS total, LAG(title, 1) OVER w AS prev `+
+		`FROM "test_posts" WHERE user_id > $1 WINDOW w AS (PARTITION BY user_id ORDER BY id ASC) ORDER BY id ASC`, query)
+	require.Equal(t, []any{0}, args)
+
+	mysqlDb := NewDb(NewConnectionFromDb(&sql.DB{}), WithDialect(MySQL{}))
+	query, _, err = mysqlDb.Table(UsersTable).Select("name").AddSelect(Rank().OverWindow("w").As("rnk")).
+		Window("w", NewWindow().OrderBy("points", "DESC")).ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT name, RANK() OVER w AS rnk FROM `test_users` WINDOW w AS (ORDER BY points DESC)", query)
+}
+
+func TestDB_WindowQuery(t *testing.T) {
+	_, err := db.Truncate(UsersTable)
+	require.NoError(t, err)
+
+	err = db.Table(UsersTable).InsertBatch(batchUsers)
+	require.NoError(t, err)
+
+	type rankedUser struct {
+		ID     int64
+		Points int64
+		Rank   int64 `db:"rnk"`
+		Total  int64
+		Prev   string
+	}
+
+	var res []rankedUser
+	user := &rankedUser{}
+	err = db.Table(UsersTable).Select("id", "points").AddSelect(
+		Rank().Over(NewWindow().OrderBy("points", "DESC")).As("rnk"),
+		WindowFn("SUM(points)").OverWindow("w").As("total"),
+		Lag("name", 1).OverWindow("w").As("prev"),
+	).Window("w", NewWindow().OrderBy("id", "ASC")).OrderBy("id", "ASC").EachToStruct(func(rows *sql.Rows) error {
+		err := db.Next(rows, user)
+		if err == nil {
+			res = append(res, *user)
+		}
+
+		return err
+	})
+	require.NoError(t, err)
+	require.Equal(t, []rankedUser{
+		{ID: 1, Points: 123, Rank: 4, Total: 123},
+		{ID: 2, Points: 1234, Rank: 3, Total: 1357, Prev: "Alex Shmidt"},
+		{ID: 3, Points: 12345, Rank: 1, Total: 13702, Prev: "Darth Vader"},
+		{ID: 4, Points: 12345, Rank: 1, Total: 26047, Prev: TestUserName},
+	}, res)
+
+	_, err = db.Truncate(UsersTable)
+	require.NoError(t, err)
+}